@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"qotd/api/internal/service"
+)
+
+// runRegrade implements `server regrade`: it regrades stored answers and
+// prints the score changes. It returns the process exit code.
+func runRegrade(ctx context.Context, cfg Config, args []string) int {
+	fs := flag.NewFlagSet("regrade", flag.ContinueOnError)
+	questionID := fs.String("question", "", "question id to regrade")
+	from := fs.String("from", "", "first publish date to regrade (YYYY-MM-DD)")
+	to := fs.String("to", "", "last publish date to regrade (YYYY-MM-DD); defaults to -from")
+	apply := fs.Bool("apply", false, "store the new scores instead of only reporting them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: server regrade (-question ID | -from YYYY-MM-DD [-to YYYY-MM-DD]) [-apply]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	scope := service.RegradeScope{QuestionID: *questionID, Apply: *apply}
+	if scope.QuestionID == "" {
+		if *to == "" {
+			*to = *from
+		}
+		var errFrom, errTo error
+		scope.From, errFrom = time.Parse(time.DateOnly, *from)
+		scope.To, errTo = time.Parse(time.DateOnly, *to)
+		if errFrom != nil || errTo != nil {
+			fs.Usage()
+			return 2
+		}
+	}
+
+	repo, _, closeStore := openStore(ctx, cfg)
+	defer closeStore()
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	svc, _ := newService(cfg, repo, logger)
+	report, err := svc.Regrade(ctx, scope)
+	if err != nil {
+		logger.Printf("regrade: %v", err)
+		return 1
+	}
+	printRegrade(os.Stdout, report)
+	return 0
+}
+
+func printRegrade(w io.Writer, report service.RegradeReport) {
+	if len(report.Changes) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "QUESTION\tANSWER\tSCORE\tTIER\tTEXT")
+		for _, c := range report.Changes {
+			fmt.Fprintf(tw, "%s\t%s\t%d -> %d\t%s -> %s\t%q\n", c.QuestionID, c.AnswerID, c.OldScore, c.NewScore, c.OldTier, c.NewTier, c.Text)
+		}
+		tw.Flush()
+	}
+	mode := "dry run, nothing stored; pass -apply to store"
+	if report.Applied {
+		mode = "applied"
+	}
+	fmt.Fprintf(w, "%d questions, %d answers, %d changed, %d skipped (appeal), %d failed (%s)\n",
+		report.Questions, report.Answers, len(report.Changes), report.Skipped, report.Failed, mode)
+}
@@ -0,0 +1,221 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuestionFilter selects questions for the admin listing. Zero fields do not
+// filter. From and To bound the publish date, inclusive; with either set,
+// unpublished questions are left out.
+type QuestionFilter struct {
+	Topic  string
+	Status string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
+
+// QuestionEdit replaces a question's editable fields. The caller derives SHA
+// and Embedding from Text, and the choice fields as for UpdateQuestionChoices.
+type QuestionEdit struct {
+	Title string
+	Text  string
+	Topic string
+	SHA   string
+	// Embedding is nil when Text is unchanged.
+	Embedding []float32
+	// Choices is nil when the choices are unchanged.
+	Choices *ChoiceUpdate
+}
+
+// ListQuestions returns a page of questions, newest publish date first with
+// unscheduled ones before them, and the number of questions matching f.
+func (r *Repository) ListQuestions(ctx context.Context, f QuestionFilter) ([]Question, int, error) {
+	var where []string
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if f.Topic != "" {
+		add("lower(topic) = lower(?)", f.Topic)
+	}
+	if f.Status != "" {
+		add("status = ?", f.Status)
+	}
+	if f.From != nil {
+		add("publish_date >= ?", *f.From)
+	}
+	if f.To != nil {
+		add("publish_date <= ?", *f.To)
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT count(*) FROM questions`+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	args = append(args, f.Limit, f.Offset)
+	rows, err := r.pool.Query(ctx, `SELECT `+questionColumns+` FROM questions`+cond+
+		fmt.Sprintf(` ORDER BY publish_date DESC NULLS FIRST, created_at DESC, id LIMIT $%d OFFSET $%d`, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []Question
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, q)
+	}
+	return out, total, rows.Err()
+}
+
+// UpdateQuestion applies an admin edit. A sha or choice signature already
+// used by another question fails with ErrDuplicate.
+func (r *Repository) UpdateQuestion(ctx context.Context, id string, e QuestionEdit) (Question, error) {
+	var vec any
+	if e.Embedding != nil {
+		vec = floatsToVectorLiteral(e.Embedding)
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Question{}, err
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `UPDATE questions SET title=$2, text=$3, topic=$4, sha256=$5, embedding=COALESCE($6::vector, embedding) WHERE id=$1`, id, e.Title, e.Text, e.Topic, e.SHA, vec)
+	if err != nil {
+		if isUniqueViolation(err, "questions_sha256_key") {
+			return Question{}, ErrDuplicateSHA
+		}
+		return Question{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return Question{}, ErrNotFound
+	}
+	if e.Choices != nil {
+		if err := updateChoices(ctx, tx, id, *e.Choices); err != nil {
+			return Question{}, err
+		}
+	}
+	q, err := scanQuestion(tx.QueryRow(ctx, `SELECT `+questionColumns+` FROM questions WHERE id=$1`, id))
+	if err != nil {
+		return Question{}, err
+	}
+	return q, tx.Commit(ctx)
+}
+
+// DeleteQuestion removes a question with its answers, disputes and cached
+// verdicts.
+func (r *Repository) DeleteQuestion(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM questions WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *MemoryStore) ListQuestions(ctx context.Context, f QuestionFilter) ([]Question, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Question
+	for _, q := range m.questions {
+		if f.Topic != "" && !strings.EqualFold(q.Topic, f.Topic) || f.Status != "" && q.Status != f.Status {
+			continue
+		}
+		if (f.From != nil || f.To != nil) && q.PublishDate == nil {
+			continue
+		}
+		if f.From != nil && q.PublishDate.Before(*f.From) || f.To != nil && q.PublishDate.After(*f.To) {
+			continue
+		}
+		out = append(out, q.copy())
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch {
+		case a.PublishDate == nil && b.PublishDate != nil:
+			return true
+		case a.PublishDate != nil && b.PublishDate == nil:
+			return false
+		case a.PublishDate != nil && !a.PublishDate.Equal(*b.PublishDate):
+			return a.PublishDate.After(*b.PublishDate)
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+	total := len(out)
+	if f.Offset >= total {
+		return nil, total, nil
+	}
+	out = out[f.Offset:]
+	if f.Limit > 0 && f.Limit < len(out) {
+		out = out[:f.Limit]
+	}
+	return out, total, nil
+}
+
+func (m *MemoryStore) UpdateQuestion(ctx context.Context, id string, e QuestionEdit) (Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q := m.byID(id)
+	if q == nil {
+		return Question{}, ErrNotFound
+	}
+	for _, other := range m.questions {
+		if other != q && other.sha == e.SHA {
+			return Question{}, ErrDuplicateSHA
+		}
+	}
+	if e.Choices != nil {
+		if err := m.checkChoiceSig(q, e.Choices.ChoiceSig); err != nil {
+			return Question{}, err
+		}
+		m.setChoices(q, *e.Choices)
+	}
+	q.Title, q.Text, q.Topic, q.sha = e.Title, e.Text, e.Topic, e.SHA
+	if e.Embedding != nil {
+		q.embedding = append([]float32(nil), e.Embedding...)
+	}
+	return q.copy(), nil
+}
+
+func (m *MemoryStore) DeleteQuestion(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q := m.byID(id)
+	if q == nil {
+		return ErrNotFound
+	}
+	m.questions = removeFrom(m.questions, func(other *memQuestion) bool { return other == q })
+	deleted := map[string]bool{}
+	m.answers = removeFrom(m.answers, func(a *memAnswer) bool {
+		deleted[a.ID] = a.QuestionID == id
+		return deleted[a.ID]
+	})
+	m.disputes = removeFrom(m.disputes, func(d *Dispute) bool { return deleted[d.AnswerID] })
+	m.dropVerdicts(id)
+	return nil
+}
+
+func removeFrom[T any](items []T, drop func(T) bool) []T {
+	out := items[:0]
+	for _, it := range items {
+		if !drop(it) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrAlreadyAnswered means the player already has a scored answer for the question.
+var ErrAlreadyAnswered = errors.New("already answered")
+
+// NewAnswer is the data needed to insert an answer. UserID is empty for
+// anonymous submissions. Practice answers are graded but do not count.
+type NewAnswer struct {
+	QuestionID string
+	UserID     string
+	Text       string
+	Score      int
+	Rubric     map[string]int
+	Feedback   string
+	Practice   bool
+	Provenance Provenance
+	// Status is AnswerGraded (the default) or AnswerPending, which stores
+	// no score and is due for grading straight away.
+	Status string
+}
+
+// Provenance records how an answer was graded. It is stored alongside the
+// score in rubric_json.
+type Provenance struct {
+	// Tier names the rule that decided the score, e.g. "exact" or "llm".
+	Tier          string  `json:"tier"`
+	CachedTier    string  `json:"cached_tier,omitempty"`
+	MatchedChoice string  `json:"matched_choice,omitempty"`
+	Similarity    float64 `json:"similarity,omitempty"`
+	Model         string  `json:"model,omitempty"`
+	PromptVersion string  `json:"prompt_version,omitempty"`
+	LatencyMS     int64   `json:"latency_ms,omitempty"`
+	RawResponse   string  `json:"raw_response,omitempty"`
+}
+
+type Answer struct {
+	ID         string
+	QuestionID string
+	UserID     string
+	Text       string
+	Score      int
+	Feedback   string
+	Practice   bool
+	Provenance Provenance
+	CreatedAt  time.Time
+	Status     string
+	// GradeAttempts counts failed tries to grade a pending answer.
+	GradeAttempts int
+}
+
+type Player struct {
+	ID        string
+	CreatedAt time.Time
+}
+
+func (r *Repository) CreatePlayer(ctx context.Context) (Player, error) {
+	var p Player
+	err := r.pool.QueryRow(ctx, `INSERT INTO players (id) VALUES (gen_random_uuid()) RETURNING id, created_at`).Scan(&p.ID, &p.CreatedAt)
+	return p, err
+}
+
+// InsertAnswer stores an answer. A second scored answer by the same player
+// for a question fails with ErrAlreadyAnswered.
+func (r *Repository) InsertAnswer(ctx context.Context, a NewAnswer) (Answer, error) {
+	status := answerStatusOrDefault(a.Status)
+	var score any = a.Score
+	rub := rubricJSON(a.Rubric, a.Score, a.Feedback, a.Provenance)
+	if status == AnswerPending {
+		score, rub = nil, ""
+	}
+	row := r.pool.QueryRow(ctx, `INSERT INTO answers (id, question_id, user_id, text, score, rubric_json, feedback, practice, status, next_grade_at) VALUES (gen_random_uuid(), $1, $2, $3, $4, NULLIF($5, '')::jsonb, $6, $7, $8, CASE WHEN $8 = 'pending' THEN now() END) RETURNING id, created_at`, a.QuestionID, nullableText(a.UserID), a.Text, score, rub, a.Feedback, a.Practice, status)
+	out := Answer{QuestionID: a.QuestionID, UserID: a.UserID, Text: a.Text, Score: a.Score, Feedback: a.Feedback, Practice: a.Practice, Provenance: a.Provenance, Status: status}
+	if err := row.Scan(&out.ID, &out.CreatedAt); err != nil {
+		if isUniqueViolation(err, "answers_scored_user_idx") {
+			return Answer{}, ErrAlreadyAnswered
+		}
+		return Answer{}, err
+	}
+	return out, nil
+}
+
+// HasScoredAnswer reports whether userID already has a non-practice answer for questionID.
+func (r *Repository) HasScoredAnswer(ctx context.Context, questionID, userID string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM answers WHERE question_id=$1 AND user_id=$2 AND NOT practice)`, questionID, userID).Scan(&exists)
+	return exists, err
+}
+
+// HistoryEntry is a player's scored answer to a published question.
+type HistoryEntry struct {
+	Question Question
+	Answer   Answer
+}
+
+func rubricJSON(rubric map[string]int, score int, feedback string, prov Provenance) string {
+	b, _ := json.Marshal(struct {
+		RubricScores map[string]int `json:"rubric_scores"`
+		Total        int            `json:"total"`
+		Feedback     string         `json:"feedback"`
+		Provenance
+	}{rubric, score, feedback, prov})
+	return string(b)
+}
+
+const answerColumns = `a.id, a.question_id, COALESCE(a.user_id::text, ''), a.text, COALESCE(a.score, 0), COALESCE(a.feedback, ''), a.practice, a.created_at, a.rubric_json, a.status, a.grade_attempts`
+
+func scanAnswer(row pgx.Row) (Answer, error) {
+	var a Answer
+	var rubric []byte
+	if err := row.Scan(&a.ID, &a.QuestionID, &a.UserID, &a.Text, &a.Score, &a.Feedback, &a.Practice, &a.CreatedAt, &rubric, &a.Status, &a.GradeAttempts); err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			return Answer{}, ErrNotFound
+		}
+		return Answer{}, err
+	}
+	a.Provenance = provenanceFromJSON(rubric)
+	return a, nil
+}
+
+func provenanceFromJSON(rubric []byte) Provenance {
+	var p Provenance
+	if len(rubric) > 0 {
+		_ = json.Unmarshal(rubric, &p)
+	}
+	return p
+}
+
+func (r *Repository) GetAnswer(ctx context.Context, id string) (Answer, error) {
+	return scanAnswer(r.pool.QueryRow(ctx, `SELECT `+answerColumns+` FROM answers a WHERE a.id=$1`, id))
+}
+
+// ListAnswers returns every answer to a question, oldest first.
+func (r *Repository) ListAnswers(ctx context.Context, questionID string) ([]Answer, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+answerColumns+` FROM answers a WHERE a.question_id=$1 ORDER BY a.created_at`, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Answer
+	for rows.Next() {
+		a, err := scanAnswer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// UpdateAnswerGrade replaces an answer's score, feedback and provenance. A
+// pending answer becomes graded.
+func (r *Repository) UpdateAnswerGrade(ctx context.Context, id string, score int, feedback string, prov Provenance) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE answers SET score=$2, feedback=$3, rubric_json=$4::jsonb, status='graded', next_grade_at=NULL WHERE id=$1`, id, score, feedback, rubricJSON(nil, score, feedback, prov))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListPlayerHistory returns userID's scored answers to published questions,
+// newest publish date first.
+func (r *Repository) ListPlayerHistory(ctx context.Context, userID string) ([]HistoryEntry, error) {
+	rows, err := r.pool.Query(ctx, `SELECT q.id, q.title, q.text, q.topic, q.created_at, q.publish_date, a.id, a.text, COALESCE(a.score, 0), COALESCE(a.feedback, ''), a.created_at, a.status
+		FROM answers a JOIN questions q ON q.id = a.question_id
+		WHERE a.user_id=$1 AND NOT a.practice AND q.publish_date IS NOT NULL
+		ORDER BY q.publish_date DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []HistoryEntry
+	for rows.Next() {
+		var e HistoryEntry
+		if err := rows.Scan(&e.Question.ID, &e.Question.Title, &e.Question.Text, &e.Question.Topic, &e.Question.CreatedAt, &e.Question.PublishDate, &e.Answer.ID, &e.Answer.Text, &e.Answer.Score, &e.Answer.Feedback, &e.Answer.CreatedAt, &e.Answer.Status); err != nil {
+			return nil, err
+		}
+		e.Answer.QuestionID = e.Question.ID
+		e.Answer.UserID = userID
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// ListPublishDates returns every date in [from, to] with a published
+// question, ascending.
+func (r *Repository) ListPublishDates(ctx context.Context, from, to time.Time) ([]time.Time, error) {
+	rows, err := r.pool.Query(ctx, `SELECT publish_date FROM questions WHERE publish_date BETWEEN $1 AND $2 AND status = 'published' ORDER BY publish_date`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []time.Time
+	for rows.Next() {
+		var d time.Time
+		if err := rows.Scan(&d); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ChoiceMatch is the accepted choice closest to an answer embedding.
+type ChoiceMatch struct {
+	Choice     string
+	Similarity float64
+}
+
+func insertChoiceEmbeddings(ctx context.Context, tx pgx.Tx, questionID string, choices []string, embeddings [][]float32) error {
+	if len(embeddings) != len(choices) {
+		return nil
+	}
+	for i, c := range choices {
+		if _, err := tx.Exec(ctx, `INSERT INTO choice_embeddings (question_id, choice, embedding) VALUES ($1, $2, $3::vector) ON CONFLICT (question_id, choice) DO UPDATE SET embedding = EXCLUDED.embedding`, questionID, c, floatsToVectorLiteral(embeddings[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NearestChoice returns the question's accepted choice most similar to emb.
+// It fails with ErrNotFound when the question has no choice embeddings.
+func (r *Repository) NearestChoice(ctx context.Context, questionID string, emb []float32) (ChoiceMatch, error) {
+	row := r.pool.QueryRow(ctx, `SELECT choice, 1 - (embedding <=> $2::vector) FROM choice_embeddings WHERE question_id=$1 ORDER BY embedding <=> $2::vector LIMIT 1`, questionID, floatsToVectorLiteral(emb))
+	var m ChoiceMatch
+	if err := row.Scan(&m.Choice, &m.Similarity); err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			return ChoiceMatch{}, ErrNotFound
+		}
+		return ChoiceMatch{}, err
+	}
+	return m, nil
+}
+
+func (m *MemoryStore) NearestChoice(ctx context.Context, questionID string, emb []float32) (ChoiceMatch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	q := m.byID(questionID)
+	if q == nil || len(q.choiceEmbeddings) == 0 {
+		return ChoiceMatch{}, ErrNotFound
+	}
+	best := ChoiceMatch{Similarity: math.Inf(-1)}
+	for i, vec := range q.choiceEmbeddings {
+		if sim := cosine(emb, vec); sim > best.Similarity {
+			best = ChoiceMatch{Choice: q.Choices[i], Similarity: sim}
+		}
+	}
+	return best, nil
+}
+
+// ChoiceUpdate replaces a question's accepted choices together with the
+// values derived from them.
+type ChoiceUpdate struct {
+	Choices    []string
+	Normalized []string
+	ChoiceSig  string
+	// Embeddings is aligned with Choices; when empty the question loses its
+	// semantic tier.
+	Embeddings [][]float32
+}
+
+// UpdateQuestionChoices stores new accepted choices. Cached grade verdicts
+// for the question are dropped since they were decided against the old
+// choices. A signature already used by another question fails with
+// ErrDuplicate.
+func (r *Repository) UpdateQuestionChoices(ctx context.Context, id string, u ChoiceUpdate) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := updateChoices(ctx, tx, id, u); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func updateChoices(ctx context.Context, tx pgx.Tx, id string, u ChoiceUpdate) error {
+	choicesJSON, _ := json.Marshal(u.Choices)
+	normalizedJSON, _ := json.Marshal(u.Normalized)
+	tag, err := tx.Exec(ctx, `UPDATE questions SET choices=$2::jsonb, choices_normalized=$3::jsonb, choices_signature=$4 WHERE id=$1`, id, string(choicesJSON), string(normalizedJSON), nullableText(u.ChoiceSig))
+	if err != nil {
+		if isUniqueViolation(err, "questions_choices_signature_idx") {
+			return ErrDuplicateChoiceSig
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM choice_embeddings WHERE question_id=$1`, id); err != nil {
+		return err
+	}
+	if err := insertChoiceEmbeddings(ctx, tx, id, u.Choices, u.Embeddings); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM grade_cache WHERE question_id=$1`, id)
+	return err
+}
+
+func (m *MemoryStore) UpdateQuestionChoices(ctx context.Context, id string, u ChoiceUpdate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q := m.byID(id)
+	if q == nil {
+		return ErrNotFound
+	}
+	if err := m.checkChoiceSig(q, u.ChoiceSig); err != nil {
+		return err
+	}
+	m.setChoices(q, u)
+	return nil
+}
+
+func (m *MemoryStore) checkChoiceSig(q *memQuestion, sig string) error {
+	if sig == "" {
+		return nil
+	}
+	for _, other := range m.questions {
+		if other != q && other.ChoiceSig == sig {
+			return ErrDuplicateChoiceSig
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) setChoices(q *memQuestion, u ChoiceUpdate) {
+	q.Choices = append([]string(nil), u.Choices...)
+	q.normalized = append([]string(nil), u.Normalized...)
+	q.ChoiceSig = u.ChoiceSig
+	q.choiceEmbeddings = nil
+	if len(u.Embeddings) == len(u.Choices) {
+		for _, e := range u.Embeddings {
+			q.choiceEmbeddings = append(q.choiceEmbeddings, append([]float32(nil), e...))
+		}
+	}
+	m.dropVerdicts(q.ID)
+}
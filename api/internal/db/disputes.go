@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrAlreadyDisputed = errors.New("answer already disputed")
+	ErrDisputeClosed   = errors.New("dispute already resolved")
+)
+
+// Dispute statuses.
+const (
+	DisputeOpen     = "open"
+	DisputeAccepted = "accepted"
+	DisputeRejected = "rejected"
+)
+
+// Dispute is a player's appeal against the grade of one of their answers.
+type Dispute struct {
+	ID         string
+	AnswerID   string
+	Reason     string
+	Status     string
+	Note       string
+	CreatedAt  time.Time
+	ResolvedAt *time.Time
+	// Answer is the disputed answer as currently graded.
+	Answer Answer
+}
+
+const disputeColumns = `d.id, d.answer_id, d.reason, d.status, d.resolution_note, d.created_at, d.resolved_at, ` + answerColumns
+
+const disputeFrom = ` FROM disputes d JOIN answers a ON a.id = d.answer_id`
+
+func scanDispute(row pgx.Row) (Dispute, error) {
+	var d Dispute
+	var rubric []byte
+	a := &d.Answer
+	err := row.Scan(&d.ID, &d.AnswerID, &d.Reason, &d.Status, &d.Note, &d.CreatedAt, &d.ResolvedAt,
+		&a.ID, &a.QuestionID, &a.UserID, &a.Text, &a.Score, &a.Feedback, &a.Practice, &a.CreatedAt, &rubric)
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			return Dispute{}, ErrNotFound
+		}
+		return Dispute{}, err
+	}
+	a.Provenance = provenanceFromJSON(rubric)
+	return d, nil
+}
+
+// InsertDispute opens a dispute on an answer. An answer can be disputed
+// once; a second dispute fails with ErrAlreadyDisputed.
+func (r *Repository) InsertDispute(ctx context.Context, answerID, reason string) (Dispute, error) {
+	var id string
+	err := r.pool.QueryRow(ctx, `INSERT INTO disputes (id, answer_id, reason) VALUES (gen_random_uuid(), $1, $2) RETURNING id`, answerID, reason).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err, "disputes_answer_idx") {
+			return Dispute{}, ErrAlreadyDisputed
+		}
+		return Dispute{}, err
+	}
+	return r.GetDispute(ctx, id)
+}
+
+func (r *Repository) GetDispute(ctx context.Context, id string) (Dispute, error) {
+	return scanDispute(r.pool.QueryRow(ctx, `SELECT `+disputeColumns+disputeFrom+` WHERE d.id=$1`, id))
+}
+
+// ListDisputes returns disputes with the given status, oldest first. An
+// empty status lists all of them.
+func (r *Repository) ListDisputes(ctx context.Context, status string) ([]Dispute, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+disputeColumns+disputeFrom+` WHERE $1 = '' OR d.status = $1 ORDER BY d.created_at`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Dispute
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// ResolveDispute closes an open dispute with status and a reviewer note.
+func (r *Repository) ResolveDispute(ctx context.Context, id, status, note string) (Dispute, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE disputes SET status=$2, resolution_note=$3, resolved_at=now() WHERE id=$1 AND status='open'`, id, status, note)
+	if err != nil {
+		return Dispute{}, err
+	}
+	d, err := r.GetDispute(ctx, id)
+	if err != nil {
+		return Dispute{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return Dispute{}, ErrDisputeClosed
+	}
+	return d, nil
+}
+
+func (m *MemoryStore) InsertDispute(ctx context.Context, answerID, reason string) (Dispute, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := m.answerByID(answerID)
+	if a == nil {
+		return Dispute{}, ErrNotFound
+	}
+	for _, d := range m.disputes {
+		if d.AnswerID == answerID {
+			return Dispute{}, ErrAlreadyDisputed
+		}
+	}
+	d := &Dispute{ID: newUUID(), AnswerID: answerID, Reason: reason, Status: DisputeOpen, CreatedAt: m.now()}
+	m.disputes = append(m.disputes, d)
+	return m.disputeView(d), nil
+}
+
+func (m *MemoryStore) GetDispute(ctx context.Context, id string) (Dispute, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, d := range m.disputes {
+		if d.ID == id {
+			return m.disputeView(d), nil
+		}
+	}
+	return Dispute{}, ErrNotFound
+}
+
+func (m *MemoryStore) ListDisputes(ctx context.Context, status string) ([]Dispute, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Dispute
+	for _, d := range m.disputes {
+		if status == "" || d.Status == status {
+			out = append(out, m.disputeView(d))
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) ResolveDispute(ctx context.Context, id, status, note string) (Dispute, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range m.disputes {
+		if d.ID != id {
+			continue
+		}
+		if d.Status != DisputeOpen {
+			return Dispute{}, ErrDisputeClosed
+		}
+		now := m.now()
+		d.Status, d.Note, d.ResolvedAt = status, note, &now
+		return m.disputeView(d), nil
+	}
+	return Dispute{}, ErrNotFound
+}
+
+func (m *MemoryStore) disputeView(d *Dispute) Dispute {
+	out := *d
+	out.ResolvedAt = copyTime(d.ResolvedAt)
+	if a := m.answerByID(d.AnswerID); a != nil {
+		out.Answer = a.Answer
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"strings"
+)
+
+// GradeVerdict is a cached grading decision for one normalized answer.
+type GradeVerdict struct {
+	Score    int
+	Feedback string
+	Tier     string
+}
+
+// GetGradeVerdict returns the cached verdict for an answer, or ErrNotFound.
+func (r *Repository) GetGradeVerdict(ctx context.Context, questionID, answerNorm string) (GradeVerdict, error) {
+	var v GradeVerdict
+	err := r.pool.QueryRow(ctx, `SELECT score, feedback, tier FROM grade_cache WHERE question_id=$1 AND answer_norm=$2`, questionID, answerNorm).Scan(&v.Score, &v.Feedback, &v.Tier)
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			return GradeVerdict{}, ErrNotFound
+		}
+		return GradeVerdict{}, err
+	}
+	return v, nil
+}
+
+// PutGradeVerdict caches v unless a verdict is already stored, and returns
+// whichever verdict is kept so concurrent graders agree.
+func (r *Repository) PutGradeVerdict(ctx context.Context, questionID, answerNorm string, v GradeVerdict) (GradeVerdict, error) {
+	var out GradeVerdict
+	err := r.pool.QueryRow(ctx, `INSERT INTO grade_cache (question_id, answer_norm, score, feedback, tier) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (question_id, answer_norm) DO UPDATE SET score = grade_cache.score
+		RETURNING score, feedback, tier`, questionID, answerNorm, v.Score, v.Feedback, v.Tier).Scan(&out.Score, &out.Feedback, &out.Tier)
+	return out, err
+}
+
+type gradeKey struct{ questionID, answerNorm string }
+
+func (m *MemoryStore) GetGradeVerdict(ctx context.Context, questionID, answerNorm string) (GradeVerdict, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.verdicts[gradeKey{questionID, answerNorm}]
+	if !ok {
+		return GradeVerdict{}, ErrNotFound
+	}
+	return v, nil
+}
+
+func (m *MemoryStore) PutGradeVerdict(ctx context.Context, questionID, answerNorm string, v GradeVerdict) (GradeVerdict, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := gradeKey{questionID, answerNorm}
+	if existing, ok := m.verdicts[key]; ok {
+		return existing, nil
+	}
+	if m.verdicts == nil {
+		m.verdicts = map[gradeKey]GradeVerdict{}
+	}
+	m.verdicts[key] = v
+	return v, nil
+}
+
+// DeleteGradeVerdicts forgets every cached verdict for a question.
+func (r *Repository) DeleteGradeVerdicts(ctx context.Context, questionID string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM grade_cache WHERE question_id=$1`, questionID)
+	return err
+}
+
+func (m *MemoryStore) DeleteGradeVerdicts(ctx context.Context, questionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropVerdicts(questionID)
+	return nil
+}
+
+func (m *MemoryStore) dropVerdicts(questionID string) {
+	for k := range m.verdicts {
+		if k.questionID == questionID {
+			delete(m.verdicts, k)
+		}
+	}
+}
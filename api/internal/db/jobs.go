@@ -0,0 +1,260 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Generation job statuses.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// Generation job kinds.
+const (
+	JobGenerate = "generate"
+	JobBacklog  = "backlog"
+)
+
+// Job is a background run generating the question for one day, or filling
+// the backlog up to Target queued questions.
+type Job struct {
+	ID     string
+	Kind   string
+	Status string
+	// PublishDate and Force are set for JobGenerate.
+	PublishDate time.Time
+	Force       bool
+	// Target is the backlog depth a JobBacklog fills up to.
+	Target int
+	// Added lists the questions a JobBacklog queued so far.
+	Added []string
+	// Attempts lists every candidate tried, in order.
+	Attempts []JobAttempt
+	// QuestionID is set once the job succeeds.
+	QuestionID string
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+// JobAttempt is one candidate question and why it was rejected. Reason is
+// empty for the accepted candidate.
+type JobAttempt struct {
+	Number int    `json:"number"`
+	Title  string `json:"title,omitempty"`
+	Text   string `json:"text,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	// Similarity is the closest existing question, once it was computed.
+	Similarity *float64 `json:"similarity,omitempty"`
+}
+
+const jobColumns = `id, kind, status, publish_date, force, target, added, attempts, question_id, error, created_at, started_at, finished_at`
+
+func scanJob(row pgx.Row) (Job, error) {
+	var j Job
+	var attempts, added []byte
+	var publishDate *time.Time
+	var questionID *string
+	if err := row.Scan(&j.ID, &j.Kind, &j.Status, &publishDate, &j.Force, &j.Target, &added, &attempts, &questionID, &j.Error, &j.CreatedAt, &j.StartedAt, &j.FinishedAt); err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			return Job{}, ErrNotFound
+		}
+		return Job{}, err
+	}
+	_ = json.Unmarshal(attempts, &j.Attempts)
+	_ = json.Unmarshal(added, &j.Added)
+	if publishDate != nil {
+		j.PublishDate = *publishDate
+	}
+	if questionID != nil {
+		j.QuestionID = *questionID
+	}
+	return j, nil
+}
+
+// InsertJob queues a job generating the question for day.
+func (r *Repository) InsertJob(ctx context.Context, day time.Time, force bool) (Job, error) {
+	return scanJob(r.pool.QueryRow(ctx, `INSERT INTO generation_jobs (id, publish_date, force) VALUES (gen_random_uuid(), $1, $2) RETURNING `+jobColumns, day, force))
+}
+
+// InsertBacklogJob queues a job filling the backlog up to target questions.
+func (r *Repository) InsertBacklogJob(ctx context.Context, target int) (Job, error) {
+	return scanJob(r.pool.QueryRow(ctx, `INSERT INTO generation_jobs (id, kind, target) VALUES (gen_random_uuid(), 'backlog', $1) RETURNING `+jobColumns, target))
+}
+
+func (r *Repository) GetJob(ctx context.Context, id string) (Job, error) {
+	return scanJob(r.pool.QueryRow(ctx, `SELECT `+jobColumns+` FROM generation_jobs WHERE id=$1`, id))
+}
+
+// ClaimJob marks the oldest queued job as running and returns it, or
+// ErrNotFound when none is queued. Concurrent callers never claim the same
+// job.
+func (r *Repository) ClaimJob(ctx context.Context) (Job, error) {
+	return scanJob(r.pool.QueryRow(ctx, `UPDATE generation_jobs SET status='running', started_at=now()
+		WHERE id = (SELECT id FROM generation_jobs WHERE status='queued' ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING `+jobColumns))
+}
+
+// HasQueuedJobs reports whether any job is waiting to be claimed.
+func (r *Repository) HasQueuedJobs(ctx context.Context) (bool, error) {
+	var ok bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM generation_jobs WHERE status='queued')`).Scan(&ok)
+	return ok, err
+}
+
+// FailRunningJobs marks every running job failed with errMsg and returns
+// how many there were.
+func (r *Repository) FailRunningJobs(ctx context.Context, errMsg string) (int, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE generation_jobs SET status='failed', error=$1, finished_at=now() WHERE status='running'`, errMsg)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// AppendJobAttempt adds an attempt to a job's list.
+func (r *Repository) AppendJobAttempt(ctx context.Context, id string, a JobAttempt) error {
+	b, err := json.Marshal([]JobAttempt{a})
+	if err != nil {
+		return err
+	}
+	return r.updateJob(ctx, `UPDATE generation_jobs SET attempts = attempts || $2::jsonb WHERE id=$1`, id, string(b))
+}
+
+// AddJobQuestion adds a question a backlog job queued to its list.
+func (r *Repository) AddJobQuestion(ctx context.Context, id, questionID string) error {
+	return r.updateJob(ctx, `UPDATE generation_jobs SET added = added || jsonb_build_array($2::text) WHERE id=$1`, id, questionID)
+}
+
+// FinishJob records a job's outcome: JobSucceeded with the question it
+// stored, or JobFailed with errMsg.
+func (r *Repository) FinishJob(ctx context.Context, id, status, questionID, errMsg string) error {
+	return r.updateJob(ctx, `UPDATE generation_jobs SET status=$2, question_id=$3, error=$4, finished_at=now() WHERE id=$1`, id, status, nullableText(questionID), errMsg)
+}
+
+func (r *Repository) updateJob(ctx context.Context, sql string, args ...any) error {
+	tag, err := r.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *MemoryStore) InsertJob(ctx context.Context, day time.Time, force bool) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := &Job{ID: newUUID(), Kind: JobGenerate, Status: JobQueued, PublishDate: day, Force: force, CreatedAt: m.now()}
+	m.jobs = append(m.jobs, j)
+	return j.copy(), nil
+}
+
+func (m *MemoryStore) InsertBacklogJob(ctx context.Context, target int) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := &Job{ID: newUUID(), Kind: JobBacklog, Status: JobQueued, Target: target, CreatedAt: m.now()}
+	m.jobs = append(m.jobs, j)
+	return j.copy(), nil
+}
+
+func (m *MemoryStore) GetJob(ctx context.Context, id string) (Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if j := m.jobByID(id); j != nil {
+		return j.copy(), nil
+	}
+	return Job{}, ErrNotFound
+}
+
+func (m *MemoryStore) ClaimJob(ctx context.Context) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, j := range m.jobs {
+		if j.Status == JobQueued {
+			now := m.now()
+			j.Status, j.StartedAt = JobRunning, &now
+			return j.copy(), nil
+		}
+	}
+	return Job{}, ErrNotFound
+}
+
+func (m *MemoryStore) HasQueuedJobs(ctx context.Context) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, j := range m.jobs {
+		if j.Status == JobQueued {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MemoryStore) FailRunningJobs(ctx context.Context, errMsg string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, j := range m.jobs {
+		if j.Status == JobRunning {
+			now := m.now()
+			j.Status, j.Error, j.FinishedAt = JobFailed, errMsg, &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) AppendJobAttempt(ctx context.Context, id string, a JobAttempt) error {
+	return m.updateJob(id, func(j *Job) { j.Attempts = append(j.Attempts, a) })
+}
+
+func (m *MemoryStore) AddJobQuestion(ctx context.Context, id, questionID string) error {
+	return m.updateJob(id, func(j *Job) { j.Added = append(j.Added, questionID) })
+}
+
+func (m *MemoryStore) FinishJob(ctx context.Context, id, status, questionID, errMsg string) error {
+	return m.updateJob(id, func(j *Job) {
+		now := m.now()
+		j.Status, j.QuestionID, j.Error, j.FinishedAt = status, questionID, errMsg, &now
+	})
+}
+
+func (m *MemoryStore) updateJob(id string, update func(*Job)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := m.jobByID(id)
+	if j == nil {
+		return ErrNotFound
+	}
+	update(j)
+	return nil
+}
+
+func (m *MemoryStore) jobByID(id string) *Job {
+	for _, j := range m.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+func (j *Job) copy() Job {
+	out := *j
+	out.Attempts = append([]JobAttempt(nil), j.Attempts...)
+	out.Added = append([]string(nil), j.Added...)
+	out.StartedAt, out.FinishedAt = copyTime(j.StartedAt), copyTime(j.FinishedAt)
+	return out
+}
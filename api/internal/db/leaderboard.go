@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// LeaderboardQuery selects publish dates in [From, To]. Location defines when
+// a day starts for answer speed.
+type LeaderboardQuery struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+	Limit    int
+}
+
+// LeaderboardRow ranks one player. AvgSeconds is the mean time from the start
+// of the publish day to each correct answer.
+type LeaderboardRow struct {
+	UserID     string
+	Correct    int
+	Streak     int
+	AvgSeconds float64
+}
+
+// Leaderboard ranks players by correct answers, then longest streak of
+// consecutive published days within the window, then average answer speed,
+// then player id so ties are deterministic. It reads player_daily_results,
+// which lags behind answers until RefreshLeaderboard runs.
+func (r *Repository) Leaderboard(ctx context.Context, lq LeaderboardQuery) ([]LeaderboardRow, error) {
+	rows, err := r.pool.Query(ctx, `WITH days AS (
+		SELECT publish_date, row_number() OVER (ORDER BY publish_date) AS day_no
+		FROM questions WHERE publish_date BETWEEN $1 AND $2 AND status = 'published'
+	), results AS (
+		SELECT r.user_id, r.publish_date, r.correct, r.answered_at, d.day_no
+		FROM player_daily_results r JOIN days d USING (publish_date)
+	), islands AS (
+		SELECT user_id, day_no - row_number() OVER (PARTITION BY user_id ORDER BY day_no) AS grp
+		FROM results WHERE correct
+	), streaks AS (
+		SELECT user_id, max(len) AS streak
+		FROM (SELECT user_id, count(*) AS len FROM islands GROUP BY user_id, grp) s
+		GROUP BY user_id
+	), totals AS (
+		SELECT user_id,
+			count(*) FILTER (WHERE correct) AS correct,
+			avg(EXTRACT(EPOCH FROM answered_at - (publish_date::timestamp AT TIME ZONE $3))) FILTER (WHERE correct) AS avg_seconds
+		FROM results GROUP BY user_id
+	)
+	SELECT t.user_id, t.correct, COALESCE(s.streak, 0), t.avg_seconds::float8
+	FROM totals t LEFT JOIN streaks s USING (user_id)
+	WHERE t.correct > 0
+	ORDER BY t.correct DESC, COALESCE(s.streak, 0) DESC, t.avg_seconds ASC, t.user_id
+	LIMIT $4`, lq.From, lq.To, lq.Location.String(), lq.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []LeaderboardRow
+	for rows.Next() {
+		var row LeaderboardRow
+		if err := rows.Scan(&row.UserID, &row.Correct, &row.Streak, &row.AvgSeconds); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// RefreshLeaderboard rebuilds player_daily_results without blocking readers.
+func (r *Repository) RefreshLeaderboard(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY player_daily_results`)
+	return err
+}
+
+func (m *MemoryStore) Leaderboard(ctx context.Context, lq LeaderboardQuery) ([]LeaderboardRow, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	dayNo := map[time.Time]int{}
+	var days []time.Time
+	for _, q := range m.questions {
+		if d := q.PublishDate; d != nil && q.Status == StatusPublished && !d.Before(lq.From) && !d.After(lq.To) {
+			days = append(days, *d)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	for i, d := range days {
+		dayNo[d] = i
+	}
+
+	type agg struct {
+		correctDays []int
+		seconds     float64
+		// first holds the earliest correct answer per day: a superseded
+		// question and its replacement count as one day.
+		first map[int]float64
+	}
+	byUser := map[string]*agg{}
+	for _, a := range m.answers {
+		if a.UserID == "" || a.Practice || a.Score <= 0 {
+			continue
+		}
+		q := m.byID(a.QuestionID)
+		if q == nil || q.PublishDate == nil {
+			continue
+		}
+		n, ok := dayNo[*q.PublishDate]
+		if !ok {
+			continue
+		}
+		u := byUser[a.UserID]
+		if u == nil {
+			u = &agg{first: map[int]float64{}}
+			byUser[a.UserID] = u
+		}
+		d := *q.PublishDate
+		start := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, lq.Location)
+		secs := a.CreatedAt.Sub(start).Seconds()
+		if prev, ok := u.first[n]; !ok || secs < prev {
+			u.first[n] = secs
+		}
+	}
+
+	out := make([]LeaderboardRow, 0, len(byUser))
+	for id, u := range byUser {
+		for n, secs := range u.first {
+			u.correctDays = append(u.correctDays, n)
+			u.seconds += secs
+		}
+		sort.Ints(u.correctDays)
+		streak, run := 0, 0
+		for i, n := range u.correctDays {
+			if i > 0 && n == u.correctDays[i-1]+1 {
+				run++
+			} else {
+				run = 1
+			}
+			if run > streak {
+				streak = run
+			}
+		}
+		out = append(out, LeaderboardRow{
+			UserID:     id,
+			Correct:    len(u.correctDays),
+			Streak:     streak,
+			AvgSeconds: u.seconds / float64(len(u.correctDays)),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Correct != b.Correct {
+			return a.Correct > b.Correct
+		}
+		if a.Streak != b.Streak {
+			return a.Streak > b.Streak
+		}
+		if a.AvgSeconds != b.AvgSeconds {
+			return a.AvgSeconds < b.AvgSeconds
+		}
+		return a.UserID < b.UserID
+	})
+	if lq.Limit > 0 && len(out) > lq.Limit {
+		out = out[:lq.Limit]
+	}
+	return out, nil
+}
+
+// RefreshLeaderboard is a no-op; MemoryStore computes rankings on demand.
+func (m *MemoryStore) RefreshLeaderboard(ctx context.Context) error { return nil }
@@ -0,0 +1,46 @@
+package db
+
+import "context"
+
+// TryLock takes a session-level Postgres advisory lock on a dedicated
+// connection, so only one API replica holds key at a time. The connection
+// returns to the pool when unlock is called.
+func (r *Repository) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+	unlock := func() {
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, key)
+		conn.Release()
+	}
+	return unlock, true, nil
+}
+
+// TryLock is a process-local lock; MemoryStore is single-instance by nature.
+func (m *MemoryStore) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.locks == nil {
+		m.locks = map[int64]bool{}
+	}
+	if m.locks[key] {
+		return nil, false, nil
+	}
+	m.locks[key] = true
+	unlock := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.locks, key)
+	}
+	return unlock, true, nil
+}
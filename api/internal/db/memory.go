@@ -0,0 +1,437 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrDuplicate reports a question whose sha or choice signature is taken.
+// The wrapping errors tell which one.
+var (
+	ErrDuplicate          = errors.New("duplicate")
+	ErrDuplicateSHA       = fmt.Errorf("questions.sha256: %w", ErrDuplicate)
+	ErrDuplicateChoiceSig = fmt.Errorf("questions.choices_signature: %w", ErrDuplicate)
+)
+
+type memQuestion struct {
+	Question
+	sha        string
+	embedding  []float32
+	normalized []string
+	// choiceEmbeddings is aligned with Choices.
+	choiceEmbeddings [][]float32
+}
+
+type memAnswer struct {
+	Answer
+	Rubric map[string]int
+	// nextGrade is when a pending answer is due for grading.
+	nextGrade time.Time
+}
+
+// MemoryStore is an in-process QuestionStore. Similarity search is a
+// brute-force cosine scan, which is fine for development-sized data.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	questions []*memQuestion
+	answers   []*memAnswer
+	players   []Player
+	locks     map[int64]bool
+	verdicts  map[gradeKey]GradeVerdict
+	disputes  []*Dispute
+	jobs      []*Job
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore { return &MemoryStore{now: time.Now} }
+
+// WithClock makes the store stamp rows using now instead of the wall clock.
+func (m *MemoryStore) WithClock(now func() time.Time) *MemoryStore {
+	m.now = now
+	return m
+}
+
+func (m *MemoryStore) GetQuestionByDate(ctx context.Context, day time.Time) (Question, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var draft *memQuestion
+	for _, q := range m.questions {
+		if q.live(day) {
+			return q.copy(), nil
+		}
+		if q.Status == StatusDraft && q.PublishDate != nil && q.PublishDate.Equal(day) {
+			draft = q
+		}
+	}
+	if draft != nil {
+		return draft.copy(), nil
+	}
+	return Question{}, ErrNotFound
+}
+
+func (m *MemoryStore) GetQuestionByID(ctx context.Context, id string) (Question, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if q := m.byID(id); q != nil {
+		return q.copy(), nil
+	}
+	return Question{}, ErrNotFound
+}
+
+func (m *MemoryStore) BacklogDepth(ctx context.Context) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n := 0
+	for _, q := range m.questions {
+		if q.queued() && (q.Status == StatusDraft || q.Status == StatusApproved) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) PromoteNext(ctx context.Context, day time.Time) (Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var next *memQuestion
+	for _, q := range m.questions {
+		if q.live(day) {
+			return Question{}, ErrDateTaken
+		}
+		if q.queued() && q.Status == StatusApproved && (next == nil || q.QueuedAt.Before(*next.QueuedAt)) {
+			next = q
+		}
+	}
+	if next == nil {
+		return Question{}, ErrNotFound
+	}
+	next.PublishDate = &day
+	next.Status = StatusPublished
+	return next.copy(), nil
+}
+
+func (m *MemoryStore) ExistsQuestionBySHA(ctx context.Context, sha string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, q := range m.questions {
+		if q.sha == sha {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MemoryStore) ExistsQuestionByChoiceSignature(ctx context.Context, sig string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, q := range m.questions {
+		if q.ChoiceSig != "" && q.ChoiceSig == sig {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MemoryStore) MaxSimilarity(ctx context.Context, emb []float32, excludeID string) (float64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	best := math.Inf(-1)
+	for _, q := range m.questions {
+		if q.ID == excludeID {
+			continue
+		}
+		if sim := cosine(emb, q.embedding); sim > best {
+			best = sim
+		}
+	}
+	if math.IsInf(best, -1) {
+		return 0, nil
+	}
+	return best, nil
+}
+
+func (m *MemoryStore) HasChoiceOverlap(ctx context.Context, normalized []string, excludeID string) (bool, error) {
+	if len(normalized) == 0 {
+		return false, nil
+	}
+	want := make(map[string]struct{}, len(normalized))
+	for _, n := range normalized {
+		want[n] = struct{}{}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, q := range m.questions {
+		if q.ID == excludeID {
+			continue
+		}
+		for _, n := range q.normalized {
+			if _, ok := want[n]; ok {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (m *MemoryStore) InsertQuestion(ctx context.Context, nq NewQuestion) (Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, q := range m.questions {
+		if q.sha == nq.SHA {
+			return Question{}, ErrDuplicateSHA
+		}
+		if nq.ChoiceSig != "" && q.ChoiceSig == nq.ChoiceSig {
+			return Question{}, ErrDuplicateChoiceSig
+		}
+		if nq.PublishDate != nil && !nq.Supersede && statusOrDefault(nq.Status) != StatusDraft && q.live(*nq.PublishDate) {
+			return Question{}, ErrDateTaken
+		}
+	}
+	if nq.Supersede && nq.PublishDate != nil {
+		for _, q := range m.questions {
+			if q.live(*nq.PublishDate) {
+				q.Status = StatusSuperseded
+			}
+		}
+	}
+	q := &memQuestion{
+		Question: Question{
+			ID:            newUUID(),
+			Title:         nq.Title,
+			Text:          nq.Text,
+			Topic:         nq.Topic,
+			CreatedAt:     m.now(),
+			Choices:       append([]string(nil), nq.Choices...),
+			ChoiceSig:     nq.ChoiceSig,
+			PublishDate:   copyTime(nq.PublishDate),
+			Options:       append([]string(nil), nq.Options...),
+			CorrectOption: nq.CorrectOption,
+			AnswerType:    answerTypeOrDefault(nq.AnswerType),
+			Status:        statusOrDefault(nq.Status),
+			Verification:  copyVerification(nq.Verification),
+		},
+		sha:        nq.SHA,
+		embedding:  append([]float32(nil), nq.Embedding...),
+		normalized: append([]string(nil), nq.Normalized...),
+	}
+	if len(nq.ChoiceEmbeddings) == len(nq.Choices) {
+		for _, e := range nq.ChoiceEmbeddings {
+			q.choiceEmbeddings = append(q.choiceEmbeddings, append([]float32(nil), e...))
+		}
+	}
+	if nq.Queued {
+		queued := q.CreatedAt
+		q.QueuedAt = &queued
+	}
+	m.questions = append(m.questions, q)
+	return q.copy(), nil
+}
+
+func (m *MemoryStore) CreatePlayer(ctx context.Context) (Player, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := Player{ID: newUUID(), CreatedAt: m.now()}
+	m.players = append(m.players, p)
+	return p, nil
+}
+
+func (m *MemoryStore) InsertAnswer(ctx context.Context, a NewAnswer) (Answer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.byID(a.QuestionID) == nil {
+		return Answer{}, fmt.Errorf("answers.question_id %s: %w", a.QuestionID, ErrNotFound)
+	}
+	if a.UserID != "" && !a.Practice && m.hasScored(a.QuestionID, a.UserID) {
+		return Answer{}, ErrAlreadyAnswered
+	}
+	ans := &memAnswer{
+		Answer: Answer{
+			ID:         newUUID(),
+			QuestionID: a.QuestionID,
+			UserID:     a.UserID,
+			Text:       a.Text,
+			Score:      a.Score,
+			Feedback:   a.Feedback,
+			Practice:   a.Practice,
+			Provenance: a.Provenance,
+			CreatedAt:  m.now(),
+			Status:     answerStatusOrDefault(a.Status),
+		},
+		Rubric: a.Rubric,
+	}
+	if ans.Status == AnswerPending {
+		ans.Score, ans.nextGrade = 0, ans.CreatedAt
+	}
+	m.answers = append(m.answers, ans)
+	return ans.Answer, nil
+}
+
+func (m *MemoryStore) GetAnswer(ctx context.Context, id string) (Answer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if a := m.answerByID(id); a != nil {
+		return a.Answer, nil
+	}
+	return Answer{}, ErrNotFound
+}
+
+func (m *MemoryStore) ListAnswers(ctx context.Context, questionID string) ([]Answer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Answer
+	for _, a := range m.answers {
+		if a.QuestionID == questionID {
+			out = append(out, a.Answer)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) UpdateAnswerGrade(ctx context.Context, id string, score int, feedback string, prov Provenance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := m.answerByID(id)
+	if a == nil {
+		return ErrNotFound
+	}
+	a.Score, a.Feedback, a.Provenance, a.Status = score, feedback, prov, AnswerGraded
+	return nil
+}
+
+func (m *MemoryStore) HasScoredAnswer(ctx context.Context, questionID, userID string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.hasScored(questionID, userID), nil
+}
+
+func (m *MemoryStore) ListPlayerHistory(ctx context.Context, userID string) ([]HistoryEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []HistoryEntry
+	for _, a := range m.answers {
+		if a.UserID != userID || a.Practice {
+			continue
+		}
+		q := m.byID(a.QuestionID)
+		if q == nil || q.PublishDate == nil {
+			continue
+		}
+		out = append(out, HistoryEntry{Question: q.copy(), Answer: a.Answer})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Question.PublishDate.After(*out[j].Question.PublishDate)
+	})
+	return out, nil
+}
+
+func (m *MemoryStore) ListPublishDates(ctx context.Context, from, to time.Time) ([]time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []time.Time
+	for _, q := range m.questions {
+		if d := q.PublishDate; d != nil && q.Status == StatusPublished && !d.Before(from) && !d.After(to) {
+			out = append(out, *d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out, nil
+}
+
+func (m *MemoryStore) hasScored(questionID, userID string) bool {
+	for _, a := range m.answers {
+		if a.QuestionID == questionID && a.UserID == userID && !a.Practice {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryStore) byID(id string) *memQuestion {
+	for _, q := range m.questions {
+		if q.ID == id {
+			return q
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) answerByID(id string) *memAnswer {
+	for _, a := range m.answers {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func (q *memQuestion) copy() Question {
+	out := q.Question
+	out.Choices = append([]string(nil), q.Choices...)
+	out.Options = append([]string(nil), q.Options...)
+	out.PublishDate = copyTime(q.PublishDate)
+	out.QueuedAt = copyTime(q.QueuedAt)
+	out.ReviewedAt = copyTime(q.ReviewedAt)
+	out.Verification = copyVerification(q.Verification)
+	return out
+}
+
+func (q *memQuestion) queued() bool { return q.PublishDate == nil && q.QueuedAt != nil }
+
+// live reports whether q is the question serving day. Dated drafts and
+// superseded questions share the date without holding it.
+func (q *memQuestion) live(day time.Time) bool {
+	return q.PublishDate != nil && q.PublishDate.Equal(day) && q.Status != StatusDraft && q.Status != StatusSuperseded
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func copyVerification(v *Verification) *Verification {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func answerTypeOrDefault(t string) string {
+	if t == "" {
+		return "free_text"
+	}
+	return t
+}
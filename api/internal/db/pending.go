@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Answer statuses.
+const (
+	AnswerGraded  = "graded"
+	AnswerPending = "pending"
+)
+
+func answerStatusOrDefault(s string) string {
+	if s == "" {
+		return AnswerGraded
+	}
+	return s
+}
+
+// ClaimDueAnswers returns up to limit pending answers due for grading at or
+// before now, the longest waiting first, and pushes them back to until so
+// that no other caller claims them meanwhile. An answer whose claimant
+// stops before grading it is due again at until.
+func (r *Repository) ClaimDueAnswers(ctx context.Context, now, until time.Time, limit int) ([]Answer, error) {
+	rows, err := r.pool.Query(ctx, `UPDATE answers a SET next_grade_at = $2
+		WHERE a.id IN (SELECT id FROM answers WHERE status = 'pending' AND next_grade_at <= $1 ORDER BY next_grade_at, created_at LIMIT $3 FOR UPDATE SKIP LOCKED)
+		RETURNING `+answerColumns, now, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Answer
+	for rows.Next() {
+		a, err := scanAnswer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// GradePendingAnswer stores the grade of a pending answer. It fails with
+// ErrNotFound once the answer is no longer pending, so an answer is graded
+// once however many callers raced to grade it.
+func (r *Repository) GradePendingAnswer(ctx context.Context, id string, score int, feedback string, prov Provenance) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE answers SET score=$2, feedback=$3, rubric_json=$4::jsonb, status='graded', next_grade_at=NULL WHERE id=$1 AND status='pending'`, id, score, feedback, rubricJSON(nil, score, feedback, prov))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeferAnswerGrade counts a failed try to grade a pending answer and sets
+// when to try again.
+func (r *Repository) DeferAnswerGrade(ctx context.Context, id string, next time.Time) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE answers SET grade_attempts = grade_attempts + 1, next_grade_at = $2 WHERE id = $1 AND status = 'pending'`, id, next)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *MemoryStore) ClaimDueAnswers(ctx context.Context, now, until time.Time, limit int) ([]Answer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []*memAnswer
+	for _, a := range m.answers {
+		if a.Status == AnswerPending && !a.nextGrade.After(now) {
+			due = append(due, a)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].nextGrade.Before(due[j].nextGrade) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	out := make([]Answer, len(due))
+	for i, a := range due {
+		a.nextGrade = until
+		out[i] = a.Answer
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) GradePendingAnswer(ctx context.Context, id string, score int, feedback string, prov Provenance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := m.answerByID(id)
+	if a == nil || a.Status != AnswerPending {
+		return ErrNotFound
+	}
+	a.Score, a.Feedback, a.Provenance, a.Status = score, feedback, prov, AnswerGraded
+	return nil
+}
+
+func (m *MemoryStore) DeferAnswerGrade(ctx context.Context, id string, next time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := m.answerByID(id)
+	if a == nil || a.Status != AnswerPending {
+		return ErrNotFound
+	}
+	a.GradeAttempts++
+	a.nextGrade = next
+	return nil
+}
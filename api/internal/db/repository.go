@@ -8,10 +8,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-var ErrNotFound = errors.New("not found")
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrDateTaken = errors.New("publish date already taken")
+)
 
 type Repository struct {
 	pool *pgxpool.Pool
@@ -27,13 +32,73 @@ type Question struct {
 	CreatedAt time.Time
 	Choices   []string
 	ChoiceSig string
+	// PublishDate is the calendar day (midnight UTC) the question is served
+	// on, or nil when it has not been scheduled.
+	PublishDate *time.Time
+	// QueuedAt is set for questions generated into the backlog.
+	QueuedAt *time.Time
+	// Options are the shuffled multiple-choice options, empty for free-text
+	// questions. CorrectOption indexes the right one.
+	Options       []string
+	CorrectOption int
+	// AnswerType is how answers are read: free_text, numeric, year or date.
+	AnswerType string
+	// Status is where the question is in review; only published questions
+	// are served. ReviewNote and ReviewedAt record the last review.
+	Status     string
+	ReviewNote string
+	ReviewedAt *time.Time
+	// Verification is nil for questions stored without a verification pass.
+	Verification *Verification
+}
+
+// Verification records a blind answer to a generated question and whether
+// it matched the question's choices.
+type Verification struct {
+	Verdict       string `json:"verdict"`
+	Answer        string `json:"answer"`
+	MatchedChoice string `json:"matched_choice,omitempty"`
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+}
+
+// VerificationPassed is the verdict stored for questions that passed.
+const VerificationPassed = "passed"
+
+// NewQuestion is the data needed to insert a question.
+type NewQuestion struct {
+	Title       string
+	Text        string
+	Topic       string
+	SHA         string
+	Embedding   []float32
+	Choices     []string
+	Normalized  []string
+	ChoiceSig   string
+	PublishDate *time.Time
+	// Queued puts the question in the backlog for later promotion.
+	Queued bool
+	// ChoiceEmbeddings holds one vector per choice, in the same order, for
+	// semantic answer matching. It may be empty.
+	ChoiceEmbeddings [][]float32
+	Options          []string
+	CorrectOption    int
+	AnswerType       string
+	// Status defaults to StatusApproved.
+	Status       string
+	Verification *Verification
+	// Supersede marks the question holding PublishDate superseded in the
+	// same transaction, so the day is never left without a question.
+	Supersede bool
 }
 
-func (r *Repository) GetLatestQuestion(ctx context.Context) (Question, error) {
-	row := r.pool.QueryRow(ctx, `SELECT id, title, text, topic, created_at, COALESCE(choices, '[]'::jsonb), choices_signature FROM questions ORDER BY created_at DESC LIMIT 1`)
+const questionColumns = `id, title, text, topic, created_at, COALESCE(choices, '[]'::jsonb), choices_signature, publish_date, queued_at, options, correct_option, answer_type, status, review_note, reviewed_at, verification`
+
+func scanQuestion(row pgx.Row) (Question, error) {
 	var q Question
-	var choicesRaw []byte
-	if err := row.Scan(&q.ID, &q.Title, &q.Text, &q.Topic, &q.CreatedAt, &choicesRaw, &q.ChoiceSig); err != nil {
+	var choicesRaw, optionsRaw, verificationRaw []byte
+	var correct *int
+	if err := row.Scan(&q.ID, &q.Title, &q.Text, &q.Topic, &q.CreatedAt, &choicesRaw, &q.ChoiceSig, &q.PublishDate, &q.QueuedAt, &optionsRaw, &correct, &q.AnswerType, &q.Status, &q.ReviewNote, &q.ReviewedAt, &verificationRaw); err != nil {
 		if strings.Contains(err.Error(), "no rows") {
 			return Question{}, ErrNotFound
 		}
@@ -42,9 +107,49 @@ func (r *Repository) GetLatestQuestion(ctx context.Context) (Question, error) {
 	if len(choicesRaw) > 0 {
 		_ = json.Unmarshal(choicesRaw, &q.Choices)
 	}
+	if len(optionsRaw) > 0 {
+		_ = json.Unmarshal(optionsRaw, &q.Options)
+	}
+	if correct != nil {
+		q.CorrectOption = *correct
+	}
+	if len(verificationRaw) > 0 {
+		var v Verification
+		if json.Unmarshal(verificationRaw, &v) == nil {
+			q.Verification = &v
+		}
+	}
 	return q, nil
 }
 
+// GetQuestionByDate returns the question serving the given calendar day or,
+// when none does, a draft dated for it.
+func (r *Repository) GetQuestionByDate(ctx context.Context, day time.Time) (Question, error) {
+	return scanQuestion(r.pool.QueryRow(ctx, `SELECT `+questionColumns+` FROM questions WHERE publish_date=$1 AND status <> 'superseded'
+		ORDER BY status = 'draft', created_at DESC LIMIT 1`, day))
+}
+
+// BacklogDepth counts queued questions that have not been published yet,
+// including drafts awaiting review.
+func (r *Repository) BacklogDepth(ctx context.Context) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `SELECT count(*) FROM questions WHERE publish_date IS NULL AND queued_at IS NOT NULL AND status IN ('draft', 'approved')`).Scan(&n)
+	return n, err
+}
+
+// PromoteNext publishes the oldest approved queued question on day. It returns
+// ErrNotFound when the backlog is empty and ErrDateTaken when day already has
+// a question.
+func (r *Repository) PromoteNext(ctx context.Context, day time.Time) (Question, error) {
+	q, err := scanQuestion(r.pool.QueryRow(ctx, `UPDATE questions SET publish_date=$1, status='published' WHERE id = (
+		SELECT id FROM questions WHERE publish_date IS NULL AND queued_at IS NOT NULL AND status = 'approved' ORDER BY queued_at, created_at LIMIT 1 FOR UPDATE SKIP LOCKED
+	) RETURNING `+questionColumns, day))
+	if err != nil && isUniqueViolation(err, "questions_publish_date_idx") {
+		return Question{}, ErrDateTaken
+	}
+	return q, err
+}
+
 func (r *Repository) ExistsQuestionBySHA(ctx context.Context, sha string) (bool, error) {
 	row := r.pool.QueryRow(ctx, `SELECT 1 FROM questions WHERE sha256=$1`, sha)
 	var one int
@@ -83,9 +188,10 @@ func floatsToVectorLiteral(v []float32) string {
 }
 
 // MaxSimilarity implements cosine similarity via pgvector <=> and returns max(1 - distance)
-func (r *Repository) MaxSimilarity(ctx context.Context, emb []float32) (float64, error) {
+// over every question but excludeID, which may be empty.
+func (r *Repository) MaxSimilarity(ctx context.Context, emb []float32, excludeID string) (float64, error) {
 	vec := floatsToVectorLiteral(emb)
-	row := r.pool.QueryRow(ctx, `SELECT 1 - (embedding <=> $1::vector) AS sim FROM questions ORDER BY embedding <=> $1::vector LIMIT 1`, vec)
+	row := r.pool.QueryRow(ctx, `SELECT 1 - (embedding <=> $1::vector) AS sim FROM questions WHERE id::text <> $2 ORDER BY embedding <=> $1::vector LIMIT 1`, vec, excludeID)
 	var sim float64
 	if err := row.Scan(&sim); err != nil {
 		if strings.Contains(err.Error(), "no rows") {
@@ -96,45 +202,67 @@ func (r *Repository) MaxSimilarity(ctx context.Context, emb []float32) (float64,
 	return sim, nil
 }
 
-func (r *Repository) InsertQuestion(ctx context.Context, title, text, topic, sha string, emb []float32, choices []string, normalized []string, choiceSig string) (Question, error) {
-	vec := floatsToVectorLiteral(emb)
+func (r *Repository) InsertQuestion(ctx context.Context, nq NewQuestion) (Question, error) {
+	vec := floatsToVectorLiteral(nq.Embedding)
 	var choicesJSON string
-	if len(choices) > 0 {
-		b, _ := json.Marshal(choices)
+	if len(nq.Choices) > 0 {
+		b, _ := json.Marshal(nq.Choices)
 		choicesJSON = string(b)
 	} else {
 		choicesJSON = "null"
 	}
 	var normalizedJSON string
-	if len(normalized) > 0 {
-		b, _ := json.Marshal(normalized)
+	if len(nq.Normalized) > 0 {
+		b, _ := json.Marshal(nq.Normalized)
 		normalizedJSON = string(b)
 	} else {
 		normalizedJSON = "null"
 	}
-	row := r.pool.QueryRow(ctx, `INSERT INTO questions (id, title, text, topic, sha256, embedding, choices, choices_normalized, choices_signature) VALUES (gen_random_uuid(), $1, $2, $3, $4, $5::vector, $6::jsonb, $7::jsonb, $8) RETURNING id, title, text, topic, created_at, choices_signature`, title, text, topic, sha, vec, choicesJSON, normalizedJSON, nullableText(choiceSig))
-	var q Question
-	if err := row.Scan(&q.ID, &q.Title, &q.Text, &q.Topic, &q.CreatedAt, &q.ChoiceSig); err != nil {
+	var optionsJSON, correct, verificationJSON any
+	if nq.Verification != nil {
+		b, _ := json.Marshal(nq.Verification)
+		verificationJSON = string(b)
+	}
+	if len(nq.Options) > 0 {
+		b, _ := json.Marshal(nq.Options)
+		optionsJSON, correct = string(b), nq.CorrectOption
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
 		return Question{}, err
 	}
-	q.Choices = choices
-	return q, nil
-}
-
-func (r *Repository) GetQuestionByID(ctx context.Context, id string) (Question, error) {
-	row := r.pool.QueryRow(ctx, `SELECT id, title, text, topic, created_at, COALESCE(choices, '[]'::jsonb), choices_signature FROM questions WHERE id=$1`, id)
-	var q Question
-	var choicesRaw []byte
-	if err := row.Scan(&q.ID, &q.Title, &q.Text, &q.Topic, &q.CreatedAt, &choicesRaw, &q.ChoiceSig); err != nil {
-		if strings.Contains(err.Error(), "no rows") {
-			return Question{}, ErrNotFound
+	defer tx.Rollback(ctx)
+	if nq.Supersede && nq.PublishDate != nil {
+		if err := supersede(ctx, tx, *nq.PublishDate); err != nil {
+			return Question{}, err
+		}
+	}
+	q, err := scanQuestion(tx.QueryRow(ctx, `INSERT INTO questions (id, title, text, topic, sha256, embedding, choices, choices_normalized, choices_signature, publish_date, queued_at, options, correct_option, answer_type, status, verification) VALUES (gen_random_uuid(), $1, $2, $3, $4, $5::vector, $6::jsonb, $7::jsonb, $8, $9, CASE WHEN $10 THEN now() END, $11::jsonb, $12, COALESCE(NULLIF($13, ''), 'free_text'), $14, $15::jsonb) RETURNING `+questionColumns, nq.Title, nq.Text, nq.Topic, nq.SHA, vec, choicesJSON, normalizedJSON, nullableText(nq.ChoiceSig), nq.PublishDate, nq.Queued, optionsJSON, correct, nq.AnswerType, statusOrDefault(nq.Status), verificationJSON))
+	if err != nil {
+		if isUniqueViolation(err, "questions_publish_date_idx") {
+			return Question{}, ErrDateTaken
 		}
 		return Question{}, err
 	}
-	if len(choicesRaw) > 0 {
-		_ = json.Unmarshal(choicesRaw, &q.Choices)
+	if err := insertChoiceEmbeddings(ctx, tx, q.ID, nq.Choices, nq.ChoiceEmbeddings); err != nil {
+		return Question{}, err
 	}
-	return q, nil
+	return q, tx.Commit(ctx)
+}
+
+// supersede retires the question serving day, leaving its date in place.
+func supersede(ctx context.Context, tx pgx.Tx, day time.Time) error {
+	_, err := tx.Exec(ctx, `UPDATE questions SET status='superseded' WHERE publish_date=$1 AND status NOT IN ('draft', 'superseded')`, day)
+	return err
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
+
+func (r *Repository) GetQuestionByID(ctx context.Context, id string) (Question, error) {
+	return scanQuestion(r.pool.QueryRow(ctx, `SELECT `+questionColumns+` FROM questions WHERE id=$1`, id))
 }
 
 func nullableText(s string) any {
@@ -144,11 +272,13 @@ func nullableText(s string) any {
 	return s
 }
 
-func (r *Repository) HasChoiceOverlap(ctx context.Context, normalized []string) (bool, error) {
+// HasChoiceOverlap reports whether a question other than excludeID, which
+// may be empty, accepts one of the normalized choices.
+func (r *Repository) HasChoiceOverlap(ctx context.Context, normalized []string, excludeID string) (bool, error) {
 	if len(normalized) == 0 {
 		return false, nil
 	}
-	row := r.pool.QueryRow(ctx, `SELECT 1 FROM questions WHERE COALESCE(choices_normalized, '[]'::jsonb) ?| $1`, normalized)
+	row := r.pool.QueryRow(ctx, `SELECT 1 FROM questions WHERE COALESCE(choices_normalized, '[]'::jsonb) ?| $1 AND id::text <> $2 LIMIT 1`, normalized, excludeID)
 	var one int
 	if err := row.Scan(&one); err != nil {
 		if strings.Contains(err.Error(), "no rows") {
@@ -158,9 +288,3 @@ func (r *Repository) HasChoiceOverlap(ctx context.Context, normalized []string)
 	}
 	return true, nil
 }
-
-func (r *Repository) InsertAnswer(ctx context.Context, questionID, text string, score int, rubric map[string]int, feedback string) error {
-	rub, _ := json.Marshal(map[string]any{"rubric_scores": rubric, "total": score, "feedback": feedback})
-	_, err := r.pool.Exec(ctx, `INSERT INTO answers (id, question_id, text, score, rubric_json, feedback) VALUES (gen_random_uuid(), $1, $2, $3, $4::jsonb, $5)`, questionID, text, score, string(rub), feedback)
-	return err
-}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNotReviewable reports a review of a question in the wrong status.
+var ErrNotReviewable = errors.New("question cannot be reviewed in its status")
+
+// Question statuses. Drafts await review; approved questions wait in the
+// backlog or unscheduled; published questions hold a publish date and are
+// served; rejected questions are never served. Superseded questions were
+// replaced on their publish date and keep it, so answers to them still
+// count for that day, but are no longer served.
+const (
+	StatusDraft      = "draft"
+	StatusApproved   = "approved"
+	StatusPublished  = "published"
+	StatusRejected   = "rejected"
+	StatusSuperseded = "superseded"
+)
+
+func statusOrDefault(s string) string {
+	if s == "" {
+		return StatusApproved
+	}
+	return s
+}
+
+// ApproveQuestion approves a draft. A draft that already holds a publish
+// date becomes published, superseding the question serving that day;
+// others become approved.
+func (r *Repository) ApproveQuestion(ctx context.Context, id, note string) (Question, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Question{}, err
+	}
+	defer tx.Rollback(ctx)
+	var day *time.Time
+	err = tx.QueryRow(ctx, `SELECT publish_date FROM questions WHERE id=$1 AND status='draft' FOR UPDATE`, id).Scan(&day)
+	if err != nil && !strings.Contains(err.Error(), "no rows") {
+		return Question{}, err
+	}
+	if day != nil {
+		if err := supersede(ctx, tx, *day); err != nil {
+			return Question{}, err
+		}
+	}
+	q, err := scanQuestion(tx.QueryRow(ctx, `UPDATE questions SET
+		status = CASE WHEN publish_date IS NULL THEN 'approved' ELSE 'published' END,
+		review_note=$2, reviewed_at=now()
+		WHERE id=$1 AND status='draft' RETURNING `+questionColumns, id, note))
+	if errors.Is(err, ErrNotFound) {
+		return Question{}, r.reviewMiss(ctx, id)
+	}
+	if err != nil {
+		return Question{}, err
+	}
+	return q, tx.Commit(ctx)
+}
+
+// RejectQuestion rejects a draft or an approved question that has not been
+// published, releasing its publish date or backlog place.
+func (r *Repository) RejectQuestion(ctx context.Context, id, note string) (Question, error) {
+	q, err := scanQuestion(r.pool.QueryRow(ctx, `UPDATE questions SET
+		status='rejected', publish_date=NULL, queued_at=NULL, review_note=$2, reviewed_at=now()
+		WHERE id=$1 AND status IN ('draft', 'approved') RETURNING `+questionColumns, id, note))
+	if errors.Is(err, ErrNotFound) {
+		return Question{}, r.reviewMiss(ctx, id)
+	}
+	return q, err
+}
+
+// reviewMiss tells a missing question from one in the wrong status.
+func (r *Repository) reviewMiss(ctx context.Context, id string) error {
+	if _, err := r.GetQuestionByID(ctx, id); err != nil {
+		return err
+	}
+	return ErrNotReviewable
+}
+
+func (m *MemoryStore) ApproveQuestion(ctx context.Context, id, note string) (Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q := m.byID(id)
+	if q == nil {
+		return Question{}, ErrNotFound
+	}
+	if q.Status != StatusDraft {
+		return Question{}, ErrNotReviewable
+	}
+	q.Status = StatusApproved
+	if q.PublishDate != nil {
+		for _, live := range m.questions {
+			if live.live(*q.PublishDate) {
+				live.Status = StatusSuperseded
+			}
+		}
+		q.Status = StatusPublished
+	}
+	m.reviewed(q, note)
+	return q.copy(), nil
+}
+
+func (m *MemoryStore) RejectQuestion(ctx context.Context, id, note string) (Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q := m.byID(id)
+	if q == nil {
+		return Question{}, ErrNotFound
+	}
+	if q.Status != StatusDraft && q.Status != StatusApproved {
+		return Question{}, ErrNotReviewable
+	}
+	q.Status = StatusRejected
+	q.PublishDate, q.QueuedAt = nil, nil
+	m.reviewed(q, note)
+	return q.copy(), nil
+}
+
+func (m *MemoryStore) reviewed(q *memQuestion, note string) {
+	now := m.now()
+	q.ReviewNote, q.ReviewedAt = note, &now
+}
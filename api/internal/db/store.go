@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// QuestionStore is the persistence surface used by the question service.
+// Repository is the Postgres implementation; MemoryStore keeps everything in
+// process for tests and Docker-free development.
+type QuestionStore interface {
+	GetQuestionByID(ctx context.Context, id string) (Question, error)
+	GetQuestionByDate(ctx context.Context, day time.Time) (Question, error)
+	BacklogDepth(ctx context.Context) (int, error)
+	PromoteNext(ctx context.Context, day time.Time) (Question, error)
+	ExistsQuestionBySHA(ctx context.Context, sha string) (bool, error)
+	ExistsQuestionByChoiceSignature(ctx context.Context, sig string) (bool, error)
+	MaxSimilarity(ctx context.Context, emb []float32, excludeID string) (float64, error)
+	HasChoiceOverlap(ctx context.Context, normalized []string, excludeID string) (bool, error)
+	InsertQuestion(ctx context.Context, q NewQuestion) (Question, error)
+	NearestChoice(ctx context.Context, questionID string, emb []float32) (ChoiceMatch, error)
+	UpdateQuestionChoices(ctx context.Context, id string, u ChoiceUpdate) error
+	ListQuestions(ctx context.Context, f QuestionFilter) ([]Question, int, error)
+	UpdateQuestion(ctx context.Context, id string, e QuestionEdit) (Question, error)
+	DeleteQuestion(ctx context.Context, id string) error
+	ApproveQuestion(ctx context.Context, id, note string) (Question, error)
+	RejectQuestion(ctx context.Context, id, note string) (Question, error)
+	InsertAnswer(ctx context.Context, a NewAnswer) (Answer, error)
+	GetAnswer(ctx context.Context, id string) (Answer, error)
+	ListAnswers(ctx context.Context, questionID string) ([]Answer, error)
+	UpdateAnswerGrade(ctx context.Context, id string, score int, feedback string, prov Provenance) error
+	ClaimDueAnswers(ctx context.Context, now, until time.Time, limit int) ([]Answer, error)
+	GradePendingAnswer(ctx context.Context, id string, score int, feedback string, prov Provenance) error
+	DeferAnswerGrade(ctx context.Context, id string, next time.Time) error
+	GetGradeVerdict(ctx context.Context, questionID, answerNorm string) (GradeVerdict, error)
+	PutGradeVerdict(ctx context.Context, questionID, answerNorm string, v GradeVerdict) (GradeVerdict, error)
+	DeleteGradeVerdicts(ctx context.Context, questionID string) error
+	HasScoredAnswer(ctx context.Context, questionID, userID string) (bool, error)
+	CreatePlayer(ctx context.Context) (Player, error)
+	ListPlayerHistory(ctx context.Context, userID string) ([]HistoryEntry, error)
+	ListPublishDates(ctx context.Context, from, to time.Time) ([]time.Time, error)
+	Leaderboard(ctx context.Context, q LeaderboardQuery) ([]LeaderboardRow, error)
+	RefreshLeaderboard(ctx context.Context) error
+	InsertDispute(ctx context.Context, answerID, reason string) (Dispute, error)
+	GetDispute(ctx context.Context, id string) (Dispute, error)
+	ListDisputes(ctx context.Context, status string) ([]Dispute, error)
+	ResolveDispute(ctx context.Context, id, status, note string) (Dispute, error)
+	InsertJob(ctx context.Context, day time.Time, force bool) (Job, error)
+	InsertBacklogJob(ctx context.Context, target int) (Job, error)
+	GetJob(ctx context.Context, id string) (Job, error)
+	ClaimJob(ctx context.Context) (Job, error)
+	HasQueuedJobs(ctx context.Context) (bool, error)
+	FailRunningJobs(ctx context.Context, errMsg string) (int, error)
+	AppendJobAttempt(ctx context.Context, id string, a JobAttempt) error
+	AddJobQuestion(ctx context.Context, id, questionID string) error
+	FinishJob(ctx context.Context, id, status, questionID, errMsg string) error
+	TryLock(ctx context.Context, key int64) (unlock func(), ok bool, err error)
+}
+
+var (
+	_ QuestionStore = (*Repository)(nil)
+	_ QuestionStore = (*MemoryStore)(nil)
+)
@@ -0,0 +1,161 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"qotd/api/internal/db"
+	"qotd/api/internal/service"
+)
+
+type reviewRequest struct {
+	Note string `json:"note"`
+}
+
+type editQuestionRequest struct {
+	Title   *string   `json:"title"`
+	Text    *string   `json:"text"`
+	Topic   *string   `json:"topic"`
+	Choices *[]string `json:"choices"`
+}
+
+// handleListQuestions pages through all questions, published, scheduled or
+// queued. Filters: ?topic=, ?status=, ?from= and ?to= (publish dates,
+// YYYY-MM-DD), ?limit= (default 50) and ?offset=.
+func (s *Server) handleListQuestions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	f := db.QuestionFilter{Topic: query.Get("topic"), Status: query.Get("status"), Limit: 50}
+	switch f.Status {
+	case "", db.StatusDraft, db.StatusApproved, db.StatusPublished, db.StatusRejected:
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "status must be draft, approved, published or rejected"})
+		return
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		if v := query.Get(p.name); v != "" {
+			day, err := time.Parse(time.DateOnly, v)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": p.name + " must be YYYY-MM-DD"})
+				return
+			}
+			*p.dst = &day
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > service.MaxQuestionPage {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(service.MaxQuestionPage)})
+			return
+		}
+		f.Limit = n
+	}
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "offset must be a non-negative integer"})
+			return
+		}
+		f.Offset = n
+	}
+	questions, total, err := s.svc.ListQuestions(r.Context(), f)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	out := make([]map[string]any, 0, len(questions))
+	for _, q := range questions {
+		out = append(out, adminQuestionJSON(q))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"questions": out, "total": total, "limit": f.Limit, "offset": f.Offset})
+}
+
+func (s *Server) handleGetQuestion(w http.ResponseWriter, r *http.Request) {
+	q, err := s.svc.Question(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, adminQuestionJSON(q))
+}
+
+// handleEditQuestion updates any of title, text, topic and choices.
+func (s *Server) handleEditQuestion(w http.ResponseWriter, r *http.Request) {
+	var req editQuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+	patch := service.QuestionPatch{Title: req.Title, Text: req.Text, Topic: req.Topic}
+	if req.Choices != nil {
+		patch.Choices = append([]string{}, *req.Choices...)
+	}
+	q, err := s.svc.EditQuestion(r.Context(), chi.URLParam(r, "id"), patch)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, adminQuestionJSON(q))
+}
+
+func (s *Server) handleApproveQuestion(w http.ResponseWriter, r *http.Request) {
+	s.reviewQuestion(w, r, s.svc.ApproveQuestion)
+}
+
+func (s *Server) handleRejectQuestion(w http.ResponseWriter, r *http.Request) {
+	s.reviewQuestion(w, r, s.svc.RejectQuestion)
+}
+
+// reviewQuestion applies a review decision with the optional note in the body.
+func (s *Server) reviewQuestion(w http.ResponseWriter, r *http.Request, review func(ctx context.Context, id, note string) (db.Question, error)) {
+	var req reviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+			return
+		}
+	}
+	q, err := review(r.Context(), chi.URLParam(r, "id"), req.Note)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, adminQuestionJSON(q))
+}
+
+func (s *Server) handleDeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.DeleteQuestion(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeQuestionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrQuestionNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "question not found"})
+	case errors.Is(err, service.ErrInvalidQuestion):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrDuplicateQuestion):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "question text matches another question"})
+	case errors.Is(err, service.ErrSimilarQuestion):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "question text is too similar to another question"})
+	case errors.Is(err, service.ErrDuplicateChoices):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "choices match another question"})
+	case errors.Is(err, service.ErrNotReviewable):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "question is not awaiting review"})
+	case errors.Is(err, service.ErrEmbedFailed):
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "embedding failed"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+	}
+}
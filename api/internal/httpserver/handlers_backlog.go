@@ -0,0 +1,56 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"qotd/api/internal/service"
+)
+
+// handleGetBacklog reports the backlog depth. With ?min=N it answers 503 when
+// fewer than N questions are queued, so uptime monitors can alert on it.
+func (s *Server) handleGetBacklog(w http.ResponseWriter, r *http.Request) {
+	depth, err := s.svc.BacklogDepth(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	resp := map[string]any{"depth": depth}
+	status := http.StatusOK
+	if v := r.URL.Query().Get("min"); v != "" {
+		min, err := strconv.Atoi(v)
+		if err != nil || min < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "min must be a non-negative integer"})
+			return
+		}
+		resp["min"] = min
+		resp["ok"] = depth >= min
+		if depth < min {
+			status = http.StatusServiceUnavailable
+		}
+	}
+	writeJSON(w, status, resp)
+}
+
+// handleFillBacklog queues a job generating questions until ?days=N
+// (default 7) are queued, and answers 202 with it; poll GET
+// /v1/admin/jobs/{id} for the outcome.
+func (s *Server) handleFillBacklog(w http.ResponseWriter, r *http.Request) {
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > service.MaxBacklogFill {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "days must be between 1 and " + strconv.Itoa(service.MaxBacklogFill)})
+			return
+		}
+		days = n
+	}
+
+	job, err := s.svc.StartBacklogFill(r.Context(), days)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	w.Header().Set("Location", "/v1/admin/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, jobJSON(job))
+}
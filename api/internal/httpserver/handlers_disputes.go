@@ -0,0 +1,144 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"qotd/api/internal/db"
+	"qotd/api/internal/service"
+)
+
+type disputeRequest struct {
+	Reason string `json:"reason"`
+}
+
+type resolveRequest struct {
+	Accept   bool   `json:"accept"`
+	AddAlias bool   `json:"add_alias"`
+	Alias    string `json:"alias"`
+	Note     string `json:"note"`
+}
+
+// handleDisputeAnswer lets a player appeal the grade of their own answer.
+func (s *Server) handleDisputeAnswer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	var req disputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+	if len(req.Reason) > 2000 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "reason too long"})
+		return
+	}
+	d, err := s.svc.DisputeAnswer(r.Context(), chi.URLParam(r, "id"), userID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAnswerNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "answer not found"})
+		case errors.Is(err, service.ErrAnswerPending):
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "answer is still being graded"})
+		case errors.Is(err, service.ErrAnswerAccepted):
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "answer already accepted"})
+		case errors.Is(err, service.ErrAlreadyDisputed):
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "already disputed"})
+		default:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"id": d.ID, "answer_id": d.AnswerID, "status": d.Status})
+}
+
+// handleListDisputes lists disputes for review, open ones by default. Use
+// ?status=accepted, rejected or all for the rest.
+func (s *Server) handleListDisputes(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = db.DisputeOpen
+	case "all":
+		status = ""
+	case db.DisputeOpen, db.DisputeAccepted, db.DisputeRejected:
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "status must be open, accepted, rejected or all"})
+		return
+	}
+	disputes, err := s.svc.Disputes(r.Context(), status)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	out := make([]map[string]any, 0, len(disputes))
+	for _, d := range disputes {
+		out = append(out, disputeJSON(d))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"disputes": out})
+}
+
+// handleResolveDispute accepts or rejects a dispute. Accepting with
+// add_alias also adds the answer (or alias) to the question's choices and
+// rescores earlier answers that now match.
+func (s *Server) handleResolveDispute(w http.ResponseWriter, r *http.Request) {
+	var req resolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+	if req.AddAlias && !req.Accept {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "add_alias requires accept"})
+		return
+	}
+	res, err := s.svc.ResolveDispute(r.Context(), chi.URLParam(r, "id"), service.Resolution{
+		Accept:   req.Accept,
+		AddAlias: req.AddAlias,
+		Alias:    strings.TrimSpace(req.Alias),
+		Note:     req.Note,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDisputeNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "dispute not found"})
+		case errors.Is(err, service.ErrDisputeClosed):
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "dispute already resolved"})
+		case errors.Is(err, service.ErrDuplicateChoices):
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "choices match another question"})
+		default:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		}
+		return
+	}
+	resp := disputeJSON(res.Dispute)
+	resp["rescored"] = res.Rescored
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func disputeJSON(d service.DisputeDetail) map[string]any {
+	resp := map[string]any{
+		"id":         d.ID,
+		"status":     d.Status,
+		"reason":     d.Reason,
+		"created_at": d.CreatedAt,
+		"answer": map[string]any{
+			"id":         d.Answer.ID,
+			"text":       d.Answer.Text,
+			"score":      d.Answer.Score,
+			"feedback":   d.Answer.Feedback,
+			"provenance": d.Answer.Provenance,
+			"created_at": d.Answer.CreatedAt,
+		},
+		"question": adminQuestionJSON(d.Question),
+	}
+	if d.ResolvedAt != nil {
+		resp["resolved_at"] = *d.ResolvedAt
+		resp["note"] = d.Note
+	}
+	return resp
+}
@@ -4,35 +4,108 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+
+	"qotd/api/internal/db"
 	"qotd/api/internal/service"
 )
 
+// handleGenerateToday queues a job generating today's question and answers
+// 202 with it; poll GET /v1/admin/jobs/{id} for the outcome.
 func (s *Server) handleGenerateToday(w http.ResponseWriter, r *http.Request) {
-	if s.cronKey == "" || r.Header.Get("X-CRON-KEY") != s.cronKey {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	force := false
+	if v := r.URL.Query().Get("force"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "force must be a boolean"})
+			return
+		}
+		force = parsed
+	}
+
+	job, err := s.svc.StartGenerate(r.Context(), service.GenerateOptions{Force: force})
+	if err != nil {
+		if errors.Is(err, service.ErrAlreadyPublished) {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "question already published for today; pass force=true to replace it"})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
 		return
 	}
+	w.Header().Set("Location", "/v1/admin/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, jobJSON(job))
+}
 
-	result, err := s.svc.GenerateQuestion(r.Context())
+// handleGetJob reports a generation job's progress. Once a generate job
+// succeeds the response includes the question it stored; a backlog job
+// lists the questions it queued so far.
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.svc.Job(r.Context(), chi.URLParam(r, "id"))
 	if err != nil {
-		if errors.Is(err, service.ErrGenerateFailed) {
-			writeJSON(w, http.StatusConflict, map[string]string{"error": "could not generate novel question"})
+		if errors.Is(err, service.ErrJobNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
 			return
 		}
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
 		return
 	}
-	resp := map[string]any{
-		"id":         result.Question.ID,
-		"title":      result.Question.Title,
-		"text":       result.Question.Text,
-		"topic":      result.Question.Topic,
-		"created_at": result.Question.CreatedAt,
-		"similarity": strconv.FormatFloat(result.Similarity, 'f', 3, 64),
+	resp := jobJSON(job)
+	if job.Kind == db.JobBacklog {
+		added := make([]map[string]any, 0, len(job.Added))
+		for _, id := range job.Added {
+			q, err := s.svc.Question(r.Context(), id)
+			if errors.Is(err, service.ErrQuestionNotFound) {
+				continue
+			}
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+				return
+			}
+			added = append(added, adminQuestionJSON(q))
+		}
+		resp["added"] = added
 	}
-	if len(result.Choices) > 0 {
-		resp["choices"] = result.Choices
+	if job.QuestionID != "" {
+		q, err := s.svc.Question(r.Context(), job.QuestionID)
+		if err != nil && !errors.Is(err, service.ErrQuestionNotFound) {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+			return
+		}
+		if err == nil {
+			resp["question"] = adminQuestionJSON(q)
+		}
 	}
 	writeJSON(w, http.StatusOK, resp)
 }
+
+func jobJSON(j db.Job) map[string]any {
+	attempts := j.Attempts
+	if attempts == nil {
+		attempts = []db.JobAttempt{}
+	}
+	resp := map[string]any{
+		"id":         j.ID,
+		"kind":       j.Kind,
+		"status":     j.Status,
+		"attempts":   attempts,
+		"created_at": j.CreatedAt,
+	}
+	if j.Kind == db.JobBacklog {
+		resp["target"] = j.Target
+	} else {
+		resp["publish_date"] = j.PublishDate.Format(time.DateOnly)
+		resp["force"] = j.Force
+	}
+	if j.StartedAt != nil {
+		resp["started_at"] = j.StartedAt
+	}
+	if j.FinishedAt != nil {
+		resp["finished_at"] = j.FinishedAt
+	}
+	if j.Error != "" {
+		resp["error"] = j.Error
+	}
+	return resp
+}
@@ -0,0 +1,63 @@
+package httpserver
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"qotd/api/internal/service"
+)
+
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	period := service.Period(r.URL.Query().Get("period"))
+	if period == "" {
+		period = service.PeriodDay
+	}
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+	lb, err := s.svc.Leaderboard(r.Context(), period, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPeriod) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "period must be day, week or all"})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	entries := make([]map[string]any, 0, len(lb.Rows))
+	for i, row := range lb.Rows {
+		entries = append(entries, map[string]any{
+			"rank":        i + 1,
+			"player_id":   row.UserID,
+			"correct":     row.Correct,
+			"streak":      row.Streak,
+			"avg_seconds": math.Round(row.AvgSeconds),
+		})
+	}
+	resp := map[string]any{
+		"period":  lb.Period,
+		"to":      lb.To.Format(time.DateOnly),
+		"entries": entries,
+	}
+	if lb.Period != service.PeriodAll {
+		resp["from"] = lb.From.Format(time.DateOnly)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleRefreshLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.RefreshLeaderboard(r.Context()); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
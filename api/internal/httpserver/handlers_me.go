@@ -0,0 +1,81 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// requirePlayer resolves the player token or writes a 401.
+func (s *Server) requirePlayer(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, err := s.playerID(r)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid player token"})
+		return "", false
+	}
+	if userID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "player token required"})
+		return "", false
+	}
+	return userID, true
+}
+
+func (s *Server) handleMyHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	limit := 30
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 365"})
+			return
+		}
+		limit = n
+	}
+	entries, err := s.svc.History(r.Context(), userID, limit)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	out := make([]map[string]any, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, map[string]any{
+			"date": e.Question.PublishDate.Format(time.DateOnly),
+			"question": map[string]any{
+				"id":    e.Question.ID,
+				"title": e.Question.Title,
+				"text":  e.Question.Text,
+				"topic": e.Question.Topic,
+			},
+			"answer": map[string]any{
+				"id":         e.Answer.ID,
+				"text":       e.Answer.Text,
+				"status":     e.Answer.Status,
+				"score":      e.Answer.Score,
+				"feedback":   e.Answer.Feedback,
+				"created_at": e.Answer.CreatedAt,
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"entries": out})
+}
+
+func (s *Server) handleMyStats(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	stats, err := s.svc.Stats(r.Context(), userID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"answered":       stats.Answered,
+		"correct":        stats.Correct,
+		"current_streak": stats.CurrentStreak,
+		"longest_streak": stats.LongestStreak,
+	})
+}
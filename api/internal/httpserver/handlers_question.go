@@ -5,7 +5,11 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+
+	"qotd/api/internal/db"
 	"qotd/api/internal/service"
 )
 
@@ -25,14 +29,67 @@ func (s *Server) handleGetToday(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{
+	writeJSON(w, http.StatusOK, questionJSON(q))
+}
+
+func (s *Server) handleGetByDate(w http.ResponseWriter, r *http.Request) {
+	day, err := time.Parse(time.DateOnly, chi.URLParam(r, "date"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "date must be YYYY-MM-DD"})
+		return
+	}
+	q, err := s.svc.GetByDate(r.Context(), day)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNoQuestion):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "no question for date"})
+		default:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, questionJSON(q))
+}
+
+func questionJSON(q db.Question) map[string]any {
+	resp := map[string]any{
 		"id":         q.ID,
 		"title":      q.Title,
 		"text":       q.Text,
 		"topic":      q.Topic,
 		"created_at": q.CreatedAt,
-		"choices":    q.Choices,
-	})
+	}
+	if q.PublishDate != nil {
+		resp["publish_date"] = q.PublishDate.Format(time.DateOnly)
+	}
+	if len(q.Options) > 0 {
+		resp["options"] = q.Options
+	}
+	if q.AnswerType != "" {
+		resp["answer_type"] = q.AnswerType
+	}
+	return resp
+}
+
+// adminQuestionJSON adds the answer key, which players must not see.
+func adminQuestionJSON(q db.Question) map[string]any {
+	resp := questionJSON(q)
+	resp["choices"] = q.Choices
+	if len(q.Options) > 0 {
+		resp["correct_option"] = q.CorrectOption
+	}
+	if q.QueuedAt != nil {
+		resp["queued_at"] = q.QueuedAt
+	}
+	resp["status"] = q.Status
+	if q.ReviewedAt != nil {
+		resp["review_note"] = q.ReviewNote
+		resp["reviewed_at"] = q.ReviewedAt
+	}
+	if q.Verification != nil {
+		resp["verification"] = q.Verification
+	}
+	return resp
 }
 
 func (s *Server) handlePostAnswer(w http.ResponseWriter, r *http.Request) {
@@ -51,15 +108,83 @@ func (s *Server) handlePostAnswer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	score, feedback, err := s.svc.SubmitAnswer(r.Context(), req.QuestionID, req.Text)
+	userID, err := s.playerID(r)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid player token"})
+		return
+	}
+
+	res, err := s.svc.SubmitAnswer(r.Context(), req.QuestionID, userID, req.Text)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrQuestionNotFound):
 			writeJSON(w, http.StatusNotFound, map[string]string{"error": "question not found"})
+		case errors.Is(err, service.ErrPlayerRequired):
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "player token required"})
+		case errors.Is(err, service.ErrAlreadyAnswered):
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "already answered"})
+		case errors.Is(err, service.ErrGradingUnavailable):
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "grading temporarily unavailable"})
 		default:
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
 		}
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"score": score, "feedback": feedback})
+	if res.Pending {
+		writeJSON(w, http.StatusAccepted, map[string]any{"id": res.ID, "status": db.AnswerPending, "feedback": res.Feedback, "practice": res.Practice})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": res.ID, "status": db.AnswerGraded, "score": res.Score, "feedback": res.Feedback, "practice": res.Practice, "tier": res.Tier})
+}
+
+// handleGradePending runs one pass of the pending answer worker now.
+func (s *Server) handleGradePending(w http.ResponseWriter, r *http.Request) {
+	n, err := s.svc.GradePending(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"graded": n})
+}
+
+// handleGetAnswer returns an answer so players can poll for the grade of a
+// pending one. Answers given with a player token need the same token.
+func (s *Server) handleGetAnswer(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.playerID(r)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid player token"})
+		return
+	}
+	a, err := s.svc.Answer(r.Context(), chi.URLParam(r, "id"), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrAnswerNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "answer not found"})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	resp := map[string]any{
+		"id":          a.ID,
+		"question_id": a.QuestionID,
+		"text":        a.Text,
+		"status":      a.Status,
+		"feedback":    a.Feedback,
+		"practice":    a.Practice,
+		"created_at":  a.CreatedAt,
+	}
+	if a.Status != db.AnswerPending {
+		resp["score"] = a.Score
+		resp["tier"] = a.Provenance.Tier
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCreatePlayer(w http.ResponseWriter, r *http.Request) {
+	p, err := s.svc.CreatePlayer(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"player_id": p.ID, "token": s.players.Sign(p.ID)})
 }
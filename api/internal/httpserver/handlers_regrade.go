@@ -0,0 +1,76 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"qotd/api/internal/service"
+)
+
+type regradeRequest struct {
+	QuestionID string `json:"question_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Apply      bool   `json:"apply"`
+}
+
+// handleRegrade regrades the answers to one question, or to the questions
+// published from..to, and reports the score changes. It is a dry run unless
+// apply is true.
+func (s *Server) handleRegrade(w http.ResponseWriter, r *http.Request) {
+	var req regradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+	scope := service.RegradeScope{QuestionID: req.QuestionID, Apply: req.Apply}
+	if req.QuestionID == "" {
+		from, errFrom := time.Parse(time.DateOnly, req.From)
+		to, errTo := time.Parse(time.DateOnly, req.To)
+		if errFrom != nil || errTo != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "question_id or from and to (YYYY-MM-DD) are required"})
+			return
+		}
+		scope.From, scope.To = from, to
+	}
+	report, err := s.svc.Regrade(r.Context(), scope)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrQuestionNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "question not found"})
+		case errors.Is(err, service.ErrRegradeScope):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "to must not be before from and the range must be at most " + strconv.Itoa(service.MaxRegradeDays) + " days"})
+		default:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "db error"})
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, regradeJSON(report))
+}
+
+func regradeJSON(report service.RegradeReport) map[string]any {
+	changes := make([]map[string]any, 0, len(report.Changes))
+	for _, c := range report.Changes {
+		changes = append(changes, map[string]any{
+			"answer_id":   c.AnswerID,
+			"question_id": c.QuestionID,
+			"text":        c.Text,
+			"old_score":   c.OldScore,
+			"new_score":   c.NewScore,
+			"old_tier":    c.OldTier,
+			"new_tier":    c.NewTier,
+			"feedback":    c.Feedback,
+		})
+	}
+	return map[string]any{
+		"applied":   report.Applied,
+		"questions": report.Questions,
+		"answers":   report.Answers,
+		"skipped":   report.Skipped,
+		"failed":    report.Failed,
+		"changes":   changes,
+	}
+}
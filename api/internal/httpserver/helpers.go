@@ -3,6 +3,9 @@ package httpserver
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+
+	"qotd/api/internal/player"
 )
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -14,8 +17,8 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 func simpleCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-CRON-KEY")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-CRON-KEY")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -23,3 +26,28 @@ func simpleCORS(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// requireCronKey rejects requests without the admin X-CRON-KEY header.
+func (s *Server) requireCronKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.cronKey == "" || r.Header.Get("X-CRON-KEY") != s.cronKey {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// playerID returns the player identified by an "Authorization: Bearer <token>"
+// header, or "" when the request carries no token.
+func (s *Server) playerID(r *http.Request) (string, error) {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if auth == "" {
+		return "", nil
+	}
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok {
+		return "", player.ErrInvalidToken
+	}
+	return s.players.Verify(token)
+}
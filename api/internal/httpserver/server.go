@@ -1,23 +1,27 @@
 package httpserver
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 
+	"qotd/api/internal/player"
 	"qotd/api/internal/service"
 )
 
 type Server struct {
 	svc     *service.QuestionService
 	cronKey string
+	players *player.Signer
 }
 
-func New(svc *service.QuestionService, cronKey string) *Server {
-	return &Server{svc: svc, cronKey: cronKey}
+func New(svc *service.QuestionService, cronKey string, players *player.Signer) *Server {
+	return &Server{svc: svc, cronKey: cronKey, players: players}
 }
 
-func (s *Server) Start(addr string) error {
+// Handler builds the router serving the API.
+func (s *Server) Handler() http.Handler {
 	r := chi.NewRouter()
 	r.Use(simpleCORS)
 
@@ -26,10 +30,37 @@ func (s *Server) Start(addr string) error {
 	})
 
 	r.Get("/v1/question/today", s.handleGetToday)
+	r.Get("/v1/question/{date}", s.handleGetByDate)
+	r.Post("/v1/players", s.handleCreatePlayer)
 	r.Post("/v1/answers", s.handlePostAnswer)
-	r.Post("/v1/admin/generate-today", s.handleGenerateToday)
+	r.Get("/v1/answers/{id}", s.handleGetAnswer)
+	r.Post("/v1/answers/{id}/dispute", s.handleDisputeAnswer)
+	r.Get("/v1/me/history", s.handleMyHistory)
+	r.Get("/v1/me/stats", s.handleMyStats)
+	r.Get("/v1/leaderboard", s.handleLeaderboard)
+	r.Group(func(r chi.Router) {
+		r.Use(s.requireCronKey)
+		r.Post("/v1/admin/generate-today", s.handleGenerateToday)
+		r.Get("/v1/admin/jobs/{id}", s.handleGetJob)
+		r.Get("/v1/admin/backlog", s.handleGetBacklog)
+		r.Post("/v1/admin/backlog/fill", s.handleFillBacklog)
+		r.Post("/v1/admin/leaderboard/refresh", s.handleRefreshLeaderboard)
+		r.Get("/v1/admin/disputes", s.handleListDisputes)
+		r.Post("/v1/admin/disputes/{id}/resolve", s.handleResolveDispute)
+		r.Post("/v1/admin/regrade", s.handleRegrade)
+		r.Post("/v1/admin/answers/grade-pending", s.handleGradePending)
+		r.Get("/v1/admin/questions", s.handleListQuestions)
+		r.Get("/v1/admin/questions/{id}", s.handleGetQuestion)
+		r.Patch("/v1/admin/questions/{id}", s.handleEditQuestion)
+		r.Delete("/v1/admin/questions/{id}", s.handleDeleteQuestion)
+		r.Post("/v1/admin/questions/{id}/approve", s.handleApproveQuestion)
+		r.Post("/v1/admin/questions/{id}/reject", s.handleRejectQuestion)
+	})
 	r.Options("/*", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	return r
+}
 
+func (s *Server) Start(addr string) error {
 	log.Printf("listening on %s", addr)
-	return http.ListenAndServe(addr, r)
+	return http.ListenAndServe(addr, s.Handler())
 }
@@ -0,0 +1,1336 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"qotd/api/internal/db"
+	"qotd/api/internal/httpserver"
+	"qotd/api/internal/llm"
+	"qotd/api/internal/llm/llmtest"
+	"qotd/api/internal/player"
+	"qotd/api/internal/service"
+)
+
+const cronKey = "test-cron-key"
+
+// now is the starting clock of every harness.
+var now = time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+type harness struct {
+	api *httptest.Server
+	llm *llmtest.Server
+	now time.Time
+}
+
+// newHarness wires the real service and router to a fake LLM server. The store
+// is in memory unless QOTD_TEST_DATABASE_URL points at a migrated Postgres.
+func newHarness(t *testing.T, configure ...func(*service.Options)) *harness {
+	t.Helper()
+	return newHarnessWith(t, llmtest.NewServer(t), configure...)
+}
+
+// newHarnessWith is newHarness with a fake LLM server the caller already
+// holds, for options that need its clients.
+func newHarnessWith(t *testing.T, fake *llmtest.Server, configure ...func(*service.Options)) *harness {
+	t.Helper()
+	h := &harness{llm: fake, now: now}
+	p := h.llm.Provider()
+	clock := func() time.Time { return h.now }
+	opts := service.Options{
+		Location: time.UTC,
+		Now:      clock,
+	}
+	for _, c := range configure {
+		c(&opts)
+	}
+	svc := service.NewQuestionService(newStore(t, clock), p.Grader, p.Embedder, p.Generator, log.New(io.Discard, "", 0), opts)
+	h.api = httptest.NewServer(httpserver.New(svc, cronKey, player.NewSigner("test-secret")).Handler())
+	t.Cleanup(h.api.Close)
+	return h
+}
+
+func newStore(t *testing.T, clock func() time.Time) db.QuestionStore {
+	t.Helper()
+	dbURL := os.Getenv("QOTD_TEST_DATABASE_URL")
+	if dbURL == "" {
+		return db.NewMemoryStore().WithClock(clock)
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("db connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	if _, err := pool.Exec(ctx, `TRUNCATE generation_jobs, disputes, answers, grade_cache, choice_embeddings, questions, players`); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	return db.NewRepository(pool)
+}
+
+func (h *harness) do(t *testing.T, method, path string, body any, headers map[string]string) (int, map[string]any) {
+	t.Helper()
+	var rdr io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		rdr = bytes.NewReader(b)
+	}
+	req, _ := http.NewRequest(method, h.api.URL+path, rdr)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	var out map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp.StatusCode, out
+}
+
+// generate starts a generation job and waits for it. A refused request
+// returns its status and body; a finished job returns 200 with the stored
+// question, or 409 with the failed job.
+func (h *harness) generate(t *testing.T, query ...string) (int, map[string]any) {
+	t.Helper()
+	path := "/v1/admin/generate-today"
+	if len(query) > 0 {
+		path += "?" + strings.Join(query, "&")
+	}
+	status, job := h.do(t, http.MethodPost, path, nil, map[string]string{"X-CRON-KEY": cronKey})
+	if status != http.StatusAccepted {
+		return status, job
+	}
+	job = h.waitJob(t, job["id"].(string))
+	if job["status"] != db.JobSucceeded {
+		return http.StatusConflict, job
+	}
+	return http.StatusOK, job["question"].(map[string]any)
+}
+
+// fillBacklog starts a backlog fill job for days and waits for it.
+func (h *harness) fillBacklog(t *testing.T, days int) map[string]any {
+	t.Helper()
+	status, job := h.do(t, http.MethodPost, "/v1/admin/backlog/fill?days="+strconv.Itoa(days), nil, map[string]string{"X-CRON-KEY": cronKey})
+	if status != http.StatusAccepted {
+		t.Fatalf("fill: %d %v", status, job)
+	}
+	return h.waitJob(t, job["id"].(string))
+}
+
+// waitJob polls a generation job until it finishes.
+func (h *harness) waitJob(t *testing.T, id string) map[string]any {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, job := h.do(t, http.MethodGet, "/v1/admin/jobs/"+id, nil, map[string]string{"X-CRON-KEY": cronKey})
+		if status != http.StatusOK {
+			t.Fatalf("get job %s: %d %v", id, status, job)
+		}
+		if job["status"] == db.JobSucceeded || job["status"] == db.JobFailed {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s still %v", id, job["status"])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (h *harness) answer(t *testing.T, questionID, text string) (int, map[string]any) {
+	return h.answerAs(t, "", questionID, text)
+}
+
+func (h *harness) answerAs(t *testing.T, token, questionID, text string) (int, map[string]any) {
+	var headers map[string]string
+	if token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + token}
+	}
+	return h.do(t, http.MethodPost, "/v1/answers", map[string]string{"question_id": questionID, "text": text}, headers)
+}
+
+func (h *harness) newPlayer(t *testing.T) string {
+	t.Helper()
+	status, body := h.do(t, http.MethodPost, "/v1/players", nil, nil)
+	token, _ := body["token"].(string)
+	if status != http.StatusCreated || token == "" {
+		t.Fatalf("create player: %d %v", status, body)
+	}
+	return token
+}
+
+// publishCapital generates capitalQuestion for today and returns its id.
+func (h *harness) publishCapital(t *testing.T) string {
+	t.Helper()
+	h.llm.QueueChat(llmtest.QuestionReply(capitalQuestion))
+	status, q := h.generate(t)
+	if status != http.StatusOK {
+		t.Fatalf("generate: %d %v", status, q)
+	}
+	return q["id"].(string)
+}
+
+var capitalQuestion = llm.Question{
+	Title:   "Capital city",
+	Text:    "Which city has been the capital of France since the late tenth century?",
+	Topic:   "geography",
+	Choices: []string{"Paris", "City of Light"},
+}
+
+func TestGenerateTodayRequiresCronKey(t *testing.T) {
+	h := newHarness(t)
+	status, _ := h.do(t, http.MethodPost, "/v1/admin/generate-today", nil, map[string]string{"X-CRON-KEY": "wrong"})
+	if status != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", status)
+	}
+	if n := len(h.llm.Requests()); n != 0 {
+		t.Fatalf("llm called %d times without auth", n)
+	}
+}
+
+func TestGetTodayBeforeGeneration(t *testing.T) {
+	h := newHarness(t)
+	status, body := h.do(t, http.MethodGet, "/v1/question/today", nil, nil)
+	if status != http.StatusNotFound || body["error"] != "no question yet" {
+		t.Fatalf("got %d %v", status, body)
+	}
+}
+
+func TestGenerateRetriesPastBadCompletions(t *testing.T) {
+	h := newHarness(t)
+	h.llm.QueueChat(
+		llmtest.ChatContent("Sure! Here is a question: {not valid json"),
+		llmtest.Status(http.StatusServiceUnavailable, `{"error":"overloaded"}`),
+		llmtest.EmptyChoices(),
+		llmtest.QuestionReply(llm.Question{Title: "No answers", Text: "Which element has the chemical symbol Fe on the periodic table?", Topic: "science"}),
+		llmtest.QuestionReply(capitalQuestion),
+	)
+	status, body := h.generate(t)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d body = %v", status, body)
+	}
+	if body["text"] != capitalQuestion.Text {
+		t.Fatalf("text = %v", body["text"])
+	}
+	if n := h.llm.Count("/v1/chat/completions"); n != 5 {
+		t.Fatalf("chat calls = %d, want 5", n)
+	}
+	if n := h.llm.Count("/v1/embeddings"); n != 3 {
+		t.Fatalf("embedding calls = %d, want 3 (question and both choices)", n)
+	}
+
+	status, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil)
+	if status != http.StatusOK || today["id"] != body["id"] {
+		t.Fatalf("today = %d %v", status, today)
+	}
+}
+
+func TestGenerateGivesUpAfterFiveAttempts(t *testing.T) {
+	h := newHarness(t)
+	for i := 0; i < 5; i++ {
+		h.llm.QueueChat(llmtest.Status(http.StatusInternalServerError, "{}"))
+	}
+	status, body := h.generate(t)
+	if status != http.StatusConflict || body["error"] != service.ErrGenerateFailed.Error() {
+		t.Fatalf("got %d %v", status, body)
+	}
+}
+
+func TestGenerateRejectsDuplicates(t *testing.T) {
+	h := newHarness(t)
+	h.llm.QueueChat(llmtest.QuestionReply(capitalQuestion))
+	if status, body := h.generate(t); status != http.StatusOK {
+		t.Fatalf("first generate: %d %v", status, body)
+	}
+
+	sameText := capitalQuestion
+	sameText.Choices = []string{"Lutetia"}
+	overlapping := llm.Question{Title: "Seine", Text: "Which European capital is divided into twenty arrondissements along the Seine?", Topic: "geography", Choices: []string{"paris"}}
+	similar := llm.Question{Title: "Old capital", Text: "Which French city on the Seine has been the national capital for over a thousand years?", Topic: "geography", Choices: []string{"Parisii"}}
+	h.llm.QueueEmbed(llmtest.Embedding(llmtest.Vector(capitalQuestion.Text)))
+	h.llm.QueueChat(
+		llmtest.QuestionReply(sameText),
+		llmtest.QuestionReply(overlapping),
+		llmtest.QuestionReply(capitalQuestion),
+		llmtest.QuestionReply(similar),
+		llmtest.QuestionReply(overlapping),
+	)
+	status, body := h.generate(t, "force=true")
+	if status != http.StatusConflict || body["error"] != service.ErrGenerateFailed.Error() {
+		t.Fatalf("status = %d body = %v", status, body)
+	}
+	if h.llm.Pending() != 0 {
+		t.Fatalf("%d scripted replies unused", h.llm.Pending())
+	}
+}
+
+func TestGenerationJob(t *testing.T) {
+	h := newHarness(t)
+	h.llm.QueueChat(llmtest.QuestionReply(capitalQuestion))
+	if status, body := h.generate(t); status != http.StatusOK {
+		t.Fatalf("first generate: %d %v", status, body)
+	}
+
+	short := llm.Question{Title: "Short", Text: "Capital of Peru?", Topic: "geography", Choices: []string{"Lima"}}
+	noChoices := llm.Question{Title: "No answers", Text: "Which element has the chemical symbol Fe on the periodic table?", Topic: "science"}
+	overlapping := llm.Question{Title: "Seine", Text: "Which European capital is divided into twenty arrondissements along the Seine?", Topic: "geography", Choices: []string{"paris"}}
+	similar := llm.Question{Title: "Old capital", Text: "Which French city on the Seine has been the national capital for over a thousand years?", Topic: "geography", Choices: []string{"Parisii"}}
+	river := llm.Question{Title: "River", Text: "Which river flows through Cairo on its way to the Mediterranean Sea?", Topic: "geography", Choices: []string{"Nile"}}
+	h.llm.QueueChat(
+		llmtest.QuestionReply(short),
+		llmtest.QuestionReply(noChoices),
+		llmtest.QuestionReply(overlapping),
+		llmtest.QuestionReply(similar),
+		llmtest.QuestionReply(river),
+	)
+	h.llm.QueueEmbed(llmtest.Embedding(llmtest.Vector(capitalQuestion.Text)))
+
+	status, job := h.do(t, http.MethodPost, "/v1/admin/generate-today?force=true", nil, map[string]string{"X-CRON-KEY": cronKey})
+	if status != http.StatusAccepted || job["status"] != db.JobQueued || job["publish_date"] != "2026-10-16" {
+		t.Fatalf("start: %d %v", status, job)
+	}
+	job = h.waitJob(t, job["id"].(string))
+	if job["status"] != db.JobSucceeded {
+		t.Fatalf("job = %v", job)
+	}
+	attempts := job["attempts"].([]any)
+	want := []string{"length", "no_choices", "choice_overlap", "similarity", ""}
+	if len(attempts) != len(want) {
+		t.Fatalf("attempts = %v", attempts)
+	}
+	for i, reason := range want {
+		a := attempts[i].(map[string]any)
+		if got, _ := a["reason"].(string); got != reason || a["number"] != float64(i+1) {
+			t.Errorf("attempt %d = %v, want reason %q", i+1, a, reason)
+		}
+	}
+	if sim, _ := attempts[3].(map[string]any)["similarity"].(float64); sim < 0.6 {
+		t.Errorf("similar attempt = %v", attempts[3])
+	}
+	if accepted := attempts[4].(map[string]any); accepted["text"] != river.Text || accepted["similarity"] == nil {
+		t.Errorf("accepted attempt = %v", accepted)
+	}
+	q := job["question"].(map[string]any)
+	if _, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil); today["id"] != q["id"] || q["text"] != river.Text {
+		t.Fatalf("today = %v, job question = %v", today, q)
+	}
+
+	if status, body := h.generate(t); status != http.StatusConflict || !strings.Contains(body["error"].(string), "already published") {
+		t.Fatalf("unforced generate: %d %v", status, body)
+	}
+	if status, _ := h.do(t, http.MethodGet, "/v1/admin/jobs/00000000-0000-0000-0000-000000000000", nil, map[string]string{"X-CRON-KEY": cronKey}); status != http.StatusNotFound {
+		t.Fatalf("unknown job: %d", status)
+	}
+}
+
+func TestSubmitAnswerPipeline(t *testing.T) {
+	h := newHarness(t)
+	h.llm.QueueChat(llmtest.QuestionReply(capitalQuestion))
+	_, q := h.generate(t)
+	id, _ := q["id"].(string)
+
+	t.Run("exact alias skips grader", func(t *testing.T) {
+		before := h.llm.Count("/v1/chat/completions")
+		status, body := h.answer(t, id, "  the city of light ")
+		if status != http.StatusOK || body["score"] != float64(10) || body["tier"] != "exact" {
+			t.Fatalf("got %d %v", status, body)
+		}
+		if h.llm.Count("/v1/chat/completions") != before {
+			t.Fatalf("grader called for exact match")
+		}
+	})
+
+	t.Run("fuzzy tiers skip grader", func(t *testing.T) {
+		before := h.llm.Count("/v1/chat/completions")
+		for answer, tier := range map[string]string{
+			"Light, City of": "token_set",
+			"Parsi":          "edit_distance",
+			"Cíty of Líght":  "folded",
+		} {
+			status, body := h.answer(t, id, answer)
+			if status != http.StatusOK || body["score"] != float64(10) || body["tier"] != tier {
+				t.Fatalf("%q: got %d %v", answer, status, body)
+			}
+		}
+		if h.llm.Count("/v1/chat/completions") != before {
+			t.Fatalf("grader called for fuzzy match")
+		}
+	})
+
+	t.Run("letter is not an option label on free-text questions", func(t *testing.T) {
+		h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: false, Reason: "Not an answer."}))
+		status, body := h.answer(t, id, "a")
+		if status != http.StatusOK || body["score"] != float64(0) {
+			t.Fatalf("got %d %v", status, body)
+		}
+	})
+
+	t.Run("grader match on listed choice", func(t *testing.T) {
+		h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Paris", Reason: "Paris, France"}))
+		status, body := h.answer(t, id, "Paris, France")
+		if status != http.StatusOK || body["score"] != float64(10) || body["feedback"] != "Paris, France" || body["tier"] != "llm" {
+			t.Fatalf("got %d %v", status, body)
+		}
+	})
+
+	t.Run("grader match on unknown alias is rejected", func(t *testing.T) {
+		h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Lyon", Reason: "close"}))
+		status, body := h.answer(t, id, "Lyon")
+		if status != http.StatusOK || body["score"] != float64(0) {
+			t.Fatalf("got %d %v", status, body)
+		}
+	})
+
+	t.Run("malformed grade is retried", func(t *testing.T) {
+		h.llm.QueueChat(
+			llmtest.ChatContent("{\"match\": tru"),
+			llmtest.GradeReply(llm.GradeResult{Match: false, Reason: "Marseille is a different city."}),
+		)
+		status, body := h.answer(t, id, "Marseille")
+		if status != http.StatusOK || body["score"] != float64(0) || body["feedback"] != "Marseille is a different city." {
+			t.Fatalf("got %d %v", status, body)
+		}
+	})
+
+	t.Run("grader unavailable", func(t *testing.T) {
+		h.llm.QueueChat(llmtest.Status(http.StatusBadGateway, "{}"))
+		status, body := h.answer(t, id, "Berlin")
+		if status != http.StatusServiceUnavailable || body["error"] != "grading temporarily unavailable" {
+			t.Fatalf("got %d %v, want 503", status, body)
+		}
+	})
+
+	t.Run("grader rejects the request", func(t *testing.T) {
+		h.llm.QueueChat(llmtest.Status(http.StatusBadRequest, `{"error":"bad request"}`))
+		status, _ := h.answer(t, id, "Berlin")
+		if status != http.StatusInternalServerError {
+			t.Fatalf("status = %d, want 500", status)
+		}
+	})
+
+	t.Run("grader returns no choices", func(t *testing.T) {
+		h.llm.QueueChat(llmtest.EmptyChoices())
+		status, _ := h.answer(t, id, "Rome")
+		if status != http.StatusInternalServerError {
+			t.Fatalf("status = %d, want 500", status)
+		}
+	})
+}
+
+func TestGradeCache(t *testing.T) {
+	h := newHarness(t, func(o *service.Options) { o.GradeCacheSize = 16 })
+	id := h.publishCapital(t)
+
+	h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: false, Reason: "Lyon is not the capital."}))
+	status, first := h.answer(t, id, "Lyon")
+	if status != http.StatusOK || first["tier"] != "llm" {
+		t.Fatalf("first: %d %v", status, first)
+	}
+	chats := h.llm.Count("/v1/chat/completions")
+	for _, answer := range []string{"lyon", " LYON! "} {
+		status, body := h.answer(t, id, answer)
+		if status != http.StatusOK || body["score"] != first["score"] || body["feedback"] != first["feedback"] || body["tier"] != "cache" {
+			t.Fatalf("%q: %d %v", answer, status, body)
+		}
+	}
+	if h.llm.Count("/v1/chat/completions") != chats {
+		t.Fatalf("grader called for a cached answer")
+	}
+}
+
+func TestSemanticTier(t *testing.T) {
+	h := newHarness(t, func(o *service.Options) {
+		o.Semantic = service.SemanticThresholds{Accept: 0.9, Reject: 0.3}
+	})
+	id := h.publishCapital(t)
+	if n := h.llm.Count("/v1/embeddings"); n != 3 {
+		t.Fatalf("embeddings during generation = %d, want question plus 2 choices", n)
+	}
+
+	// ambiguous sits at cosine 0.6 from "Paris", between the thresholds.
+	paris, other := llmtest.Vector("Paris"), llmtest.Vector("unrelated")
+	ambiguous := make([]float32, len(paris))
+	for i := range paris {
+		ambiguous[i] = 0.6*paris[i] + 0.8*other[i]
+	}
+
+	chats := h.llm.Count("/v1/chat/completions")
+	h.llm.QueueEmbed(llmtest.Embedding(paris))
+	if status, body := h.answer(t, id, "The French capital"); status != http.StatusOK || body["score"] != float64(10) || body["tier"] != "semantic" {
+		t.Fatalf("close answer: %d %v", status, body)
+	}
+	if status, body := h.answer(t, id, "Berlin"); status != http.StatusOK || body["score"] != float64(0) || body["tier"] != "semantic" {
+		t.Fatalf("distant answer: %d %v", status, body)
+	}
+	if h.llm.Count("/v1/chat/completions") != chats {
+		t.Fatalf("grader called outside the ambiguous band")
+	}
+
+	h.llm.QueueEmbed(llmtest.Embedding(ambiguous))
+	h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Paris", Reason: "Capital of France."}))
+	if status, body := h.answer(t, id, "Capital of France"); status != http.StatusOK || body["score"] != float64(10) || body["tier"] != "llm" {
+		t.Fatalf("ambiguous answer: %d %v", status, body)
+	}
+}
+
+func TestMultipleChoice(t *testing.T) {
+	h := newHarness(t)
+	mc := capitalQuestion
+	mc.Correct = "Paris"
+	mc.Choices = []string{"City of Light"}
+	badDistractors := mc
+	badDistractors.Distractors = []string{"Lyon", "Marseille", "paris"}
+	mc.Distractors = []string{"Lyon", "Marseille", "Orléans"}
+	h.llm.QueueChat(llmtest.QuestionReply(badDistractors), llmtest.QuestionReply(mc))
+
+	status, gen := h.generate(t)
+	if status != http.StatusOK {
+		t.Fatalf("generate: %d %v", status, gen)
+	}
+	id := gen["id"].(string)
+	options, _ := gen["options"].([]any)
+	if len(options) != 4 {
+		t.Fatalf("options = %v", gen["options"])
+	}
+	correct := int(gen["correct_option"].(float64))
+	if options[correct] != "Paris" {
+		t.Fatalf("correct_option %d points at %v", correct, options[correct])
+	}
+	choices, _ := gen["choices"].([]any)
+	if len(choices) != 2 || choices[0] != "Paris" {
+		t.Fatalf("choices = %v, want correct answer added to aliases", choices)
+	}
+
+	_, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil)
+	if len(today["options"].([]any)) != 4 {
+		t.Fatalf("public question = %v", today)
+	}
+	for _, key := range []string{"choices", "correct_option"} {
+		if _, ok := today[key]; ok {
+			t.Fatalf("public question leaks %s: %v", key, today)
+		}
+	}
+
+	wrong := (correct + 1) % 4
+	chats := h.llm.Count("/v1/chat/completions")
+	for _, tc := range []struct {
+		answer string
+		score  float64
+	}{
+		{string(rune('a' + correct)), 10},
+		{"(" + string(rune('1'+correct)) + ")", 10},
+		{"paris", 10},
+		{string(rune('A' + wrong)), 0},
+		{options[wrong].(string), 0},
+	} {
+		status, body := h.answer(t, id, tc.answer)
+		if status != http.StatusOK || body["score"] != tc.score {
+			t.Fatalf("answer %q: %d %v", tc.answer, status, body)
+		}
+	}
+	if h.llm.Count("/v1/chat/completions") != chats {
+		t.Fatalf("grader called for option answers")
+	}
+
+	admin := map[string]string{"X-CRON-KEY": cronKey}
+	for _, choices := range [][]string{{"City of Light"}, {"Paris", options[wrong].(string)}} {
+		if status, body := h.do(t, http.MethodPatch, "/v1/admin/questions/"+id, map[string]any{"choices": choices}, admin); status != http.StatusBadRequest {
+			t.Errorf("choices %v: %d %v", choices, status, body)
+		}
+	}
+	status, edited := h.do(t, http.MethodPatch, "/v1/admin/questions/"+id, map[string]any{"choices": []string{"Paris", "Lutetia"}}, admin)
+	if status != http.StatusOK || edited["correct_option"] != float64(correct) {
+		t.Fatalf("edit choices: %d %v", status, edited)
+	}
+}
+
+func TestTypedAnswers(t *testing.T) {
+	h := newHarness(t, func(o *service.Options) {
+		o.Tolerance = service.Tolerance{Numeric: 0.01}
+	})
+	cases := []struct {
+		question llm.Question
+		answers  map[string]float64
+	}{
+		{
+			question: llm.Question{Title: "Moon landing", Text: "In which year did Apollo 11 first land people on the Moon?", Topic: "history", AnswerType: llm.AnswerYear, Choices: []string{"1969"}},
+			answers:  map[string]float64{"nineteen sixty-nine": 10, "1969 AD": 10, "1969.": 10, "1969!": 10, "1970": 0},
+		},
+		{
+			question: llm.Question{Title: "Everest", Text: "How tall is Mount Everest above sea level, in metres, per the 2020 survey?", Topic: "geography", AnswerType: llm.AnswerNumeric, Choices: []string{"8849 m"}},
+			answers:  map[string]float64{"about 8,849 m": 10, "29,032 ft": 10, "8.85 km": 10, "9000": 0},
+		},
+		{
+			question: llm.Question{Title: "Landing date", Text: "On what date did the Apollo 11 lunar module land on the Moon?", Topic: "history", AnswerType: llm.AnswerDate, Choices: []string{"1969-07-20"}},
+			answers:  map[string]float64{"July 20th, 1969": 10, "20 Jul 1969.": 10, "July 21, 1969": 0},
+		},
+	}
+	for _, c := range cases {
+		h.llm.QueueChat(llmtest.QuestionReply(c.question))
+		status, gen := h.generate(t)
+		if status != http.StatusOK || gen["answer_type"] != string(c.question.AnswerType) {
+			t.Fatalf("generate: %d %v", status, gen)
+		}
+		chats := h.llm.Count("/v1/chat/completions")
+		for answer, want := range c.answers {
+			status, body := h.answer(t, gen["id"].(string), answer)
+			if status != http.StatusOK || body["score"] != want {
+				t.Fatalf("%s: answer %q: %d %v", c.question.Title, answer, status, body)
+			}
+		}
+		if h.llm.Count("/v1/chat/completions") != chats {
+			t.Fatalf("%s: grader called for typed answers", c.question.Title)
+		}
+		h.now = h.now.Add(24 * time.Hour)
+	}
+
+	mistyped := capitalQuestion
+	mistyped.AnswerType = llm.AnswerDate
+	h.llm.QueueChat(llmtest.QuestionReply(mistyped))
+	if status, gen := h.generate(t); status != http.StatusOK || gen["answer_type"] != string(llm.AnswerFreeText) {
+		t.Fatalf("mistyped generate: %d %v", status, gen)
+	}
+}
+
+func TestTypedAnswersFallThrough(t *testing.T) {
+	h := newHarness(t)
+	h.llm.QueueChat(llmtest.QuestionReply(llm.Question{Title: "Moon landing", Text: "In which year did Apollo 11 first land people on the Moon?", Topic: "history", AnswerType: llm.AnswerYear, Choices: []string{"1969"}}))
+	status, gen := h.generate(t)
+	if status != http.StatusOK || gen["answer_type"] != string(llm.AnswerYear) {
+		t.Fatalf("generate: %d %v", status, gen)
+	}
+	// Answers that do not parse as a year go on to the grader instead of
+	// being marked wrong.
+	for _, answer := range []string{"July 1969", "'69"} {
+		h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "1969", Reason: "Same year."}))
+		status, body := h.answer(t, gen["id"].(string), answer)
+		if status != http.StatusOK || body["score"] != float64(10) || body["tier"] != "llm" {
+			t.Fatalf("answer %q: %d %v", answer, status, body)
+		}
+	}
+	h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: false, Reason: "Not a year."}))
+	if status, body := h.answer(t, gen["id"].(string), "sometime"); status != http.StatusOK || body["score"] != float64(0) {
+		t.Fatalf("answer %q: %d %v", "sometime", status, body)
+	}
+}
+
+func TestSubmitAnswerValidation(t *testing.T) {
+	h := newHarness(t)
+	status, _ := h.answer(t, "00000000-0000-0000-0000-000000000000", "anything")
+	if status != http.StatusNotFound {
+		t.Fatalf("unknown question status = %d", status)
+	}
+	status, _ = h.answer(t, "", "anything")
+	if status != http.StatusBadRequest {
+		t.Fatalf("missing id status = %d", status)
+	}
+}
+
+func TestPublishDates(t *testing.T) {
+	h := newHarness(t)
+	h.llm.QueueChat(llmtest.QuestionReply(capitalQuestion))
+	status, first := h.generate(t)
+	if status != http.StatusOK || first["publish_date"] != "2026-10-16" {
+		t.Fatalf("generate: %d %v", status, first)
+	}
+
+	status, body := h.generate(t)
+	if status != http.StatusConflict || !strings.Contains(body["error"].(string), "already published") {
+		t.Fatalf("second generate: %d %v", status, body)
+	}
+	if n := len(h.llm.Requests()); n != 4 {
+		t.Fatalf("llm requests = %d; second generate should not call the llm", n)
+	}
+
+	alice := h.newPlayer(t)
+	auth := map[string]string{"Authorization": "Bearer " + alice}
+	if status, body := h.answerAs(t, alice, first["id"].(string), "Paris"); status != http.StatusOK || body["score"] != float64(10) {
+		t.Fatalf("answer: %d %v", status, body)
+	}
+
+	// A forced regenerate that fails leaves the day's question in place.
+	for i := 0; i < 5; i++ {
+		h.llm.QueueChat(llmtest.Status(http.StatusInternalServerError, "{}"))
+	}
+	if status, body := h.generate(t, "force=true"); status != http.StatusConflict {
+		t.Fatalf("failed forced generate: %d %v", status, body)
+	}
+	if status, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil); status != http.StatusOK || today["id"] != first["id"] {
+		t.Fatalf("today after failed force: %d %v", status, today)
+	}
+
+	h.llm.QueueChat(llmtest.QuestionReply(llm.Question{Title: "Tallest", Text: "What is the name of the tallest mountain above sea level on Earth?", Topic: "geography", Choices: []string{"Everest"}}))
+	status, forced := h.generate(t, "force=true")
+	if status != http.StatusOK || forced["id"] == first["id"] {
+		t.Fatalf("forced generate: %d %v", status, forced)
+	}
+
+	// The replaced question keeps its day, so earlier answers still count.
+	admin := map[string]string{"X-CRON-KEY": cronKey}
+	if status, old := h.do(t, http.MethodGet, "/v1/admin/questions/"+first["id"].(string), nil, admin); status != http.StatusOK || old["status"] != db.StatusSuperseded || old["publish_date"] != "2026-10-16" {
+		t.Fatalf("replaced question: %d %v", status, old)
+	}
+	_, history := h.do(t, http.MethodGet, "/v1/me/history", nil, auth)
+	if entries, _ := history["entries"].([]any); len(entries) != 1 || entries[0].(map[string]any)["question"].(map[string]any)["id"] != first["id"] {
+		t.Fatalf("history = %v", history)
+	}
+	if _, stats := h.do(t, http.MethodGet, "/v1/me/stats", nil, auth); stats["current_streak"] != float64(1) {
+		t.Fatalf("stats = %v", stats)
+	}
+
+	status, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil)
+	if status != http.StatusOK || today["id"] != forced["id"] {
+		t.Fatalf("today: %d %v", status, today)
+	}
+	status, byDate := h.do(t, http.MethodGet, "/v1/question/2026-10-16", nil, nil)
+	if status != http.StatusOK || byDate["id"] != forced["id"] {
+		t.Fatalf("by date: %d %v", status, byDate)
+	}
+
+	for path, want := range map[string]int{
+		"/v1/question/2026-10-15": http.StatusNotFound,
+		"/v1/question/2026-10-17": http.StatusNotFound,
+		"/v1/question/16-10-2026": http.StatusBadRequest,
+	} {
+		if status, body := h.do(t, http.MethodGet, path, nil, nil); status != want {
+			t.Errorf("%s: %d %v, want %d", path, status, body, want)
+		}
+	}
+}
+
+func TestBacklogPromotion(t *testing.T) {
+	h := newHarness(t)
+	admin := map[string]string{"X-CRON-KEY": cronKey}
+	everest := llm.Question{Title: "Tallest", Text: "What is the name of the tallest mountain above sea level on Earth?", Topic: "geography", Choices: []string{"Everest"}}
+	h.llm.QueueChat(llmtest.QuestionReply(capitalQuestion), llmtest.QuestionReply(everest))
+
+	if status, body := h.do(t, http.MethodPost, "/v1/admin/backlog/fill?days=61", nil, admin); status != http.StatusBadRequest {
+		t.Fatalf("fill too many: %d %v", status, body)
+	}
+	job := h.fillBacklog(t, 2)
+	if job["kind"] != "backlog" || job["status"] != db.JobSucceeded || job["target"] != float64(2) || len(job["added"].([]any)) != 2 {
+		t.Fatalf("fill: %v", job)
+	}
+	if _, body := h.do(t, http.MethodGet, "/v1/admin/backlog", nil, admin); body["depth"] != float64(2) {
+		t.Fatalf("depth after fill: %v", body)
+	}
+	if status, body := h.do(t, http.MethodGet, "/v1/admin/backlog?min=3", nil, admin); status != http.StatusServiceUnavailable || body["ok"] != false {
+		t.Fatalf("depth check: %d %v", status, body)
+	}
+
+	_, first := h.do(t, http.MethodGet, "/v1/question/today", nil, nil)
+	if first["text"] != capitalQuestion.Text || first["publish_date"] != "2026-10-16" {
+		t.Fatalf("today: %v", first)
+	}
+	_, again := h.do(t, http.MethodGet, "/v1/question/today", nil, nil)
+	if again["id"] != first["id"] {
+		t.Fatalf("today changed mid-day: %v then %v", first["id"], again["id"])
+	}
+
+	h.now = h.now.Add(24 * time.Hour)
+	_, second := h.do(t, http.MethodGet, "/v1/question/today", nil, nil)
+	if second["text"] != everest.Text || second["publish_date"] != "2026-10-17" {
+		t.Fatalf("next day: %v", second)
+	}
+	if _, body := h.do(t, http.MethodGet, "/v1/admin/backlog", nil, admin); body["depth"] != float64(0) {
+		t.Fatalf("depth after promotions: %v", body)
+	}
+
+	h.now = h.now.Add(24 * time.Hour)
+	if status, _ := h.do(t, http.MethodGet, "/v1/question/today", nil, nil); status != http.StatusNotFound {
+		t.Fatalf("empty backlog status = %d", status)
+	}
+	if n := h.llm.Count("/v1/chat/completions"); n != 2 {
+		t.Fatalf("chat calls = %d; promotion must not call the llm", n)
+	}
+}
+
+func TestAttemptPolicyFirstScored(t *testing.T) {
+	h := newHarness(t)
+	id := h.publishCapital(t)
+	token := h.newPlayer(t)
+
+	status, first := h.answerAs(t, token, id, "Paris")
+	if status != http.StatusOK || first["practice"] != false || first["score"] != float64(10) || first["id"] == "" {
+		t.Fatalf("first: %d %v", status, first)
+	}
+	status, second := h.answerAs(t, token, id, "City of Light")
+	if status != http.StatusOK || second["practice"] != true || second["score"] != float64(10) {
+		t.Fatalf("second: %d %v", status, second)
+	}
+	status, anon := h.answer(t, id, "Paris")
+	if status != http.StatusOK || anon["practice"] != true {
+		t.Fatalf("anonymous: %d %v", status, anon)
+	}
+	other := h.newPlayer(t)
+	if status, body := h.answerAs(t, other, id, "Paris"); status != http.StatusOK || body["practice"] != false {
+		t.Fatalf("other player: %d %v", status, body)
+	}
+}
+
+func TestAttemptPolicySingle(t *testing.T) {
+	h := newHarness(t, func(o *service.Options) { o.AttemptPolicy = service.PolicySingle })
+	id := h.publishCapital(t)
+	token := h.newPlayer(t)
+
+	if status, body := h.answerAs(t, token, id, "Paris"); status != http.StatusOK || body["practice"] != false {
+		t.Fatalf("first: %d %v", status, body)
+	}
+	if status, body := h.answerAs(t, token, id, "Paris"); status != http.StatusConflict {
+		t.Fatalf("second: %d %v", status, body)
+	}
+	if status, body := h.answer(t, id, "Paris"); status != http.StatusUnauthorized {
+		t.Fatalf("anonymous: %d %v", status, body)
+	}
+	if status, body := h.answerAs(t, token+"x", id, "Paris"); status != http.StatusUnauthorized || body["error"] != "invalid player token" {
+		t.Fatalf("forged token: %d %v", status, body)
+	}
+}
+
+func TestHistoryAndStreaks(t *testing.T) {
+	h := newHarness(t)
+	token := h.newPlayer(t)
+	auth := map[string]string{"Authorization": "Bearer " + token}
+	days := []llm.Question{
+		capitalQuestion,
+		{Title: "Tallest", Text: "What is the name of the tallest mountain above sea level on Earth?", Topic: "geography", Choices: []string{"Everest"}},
+		{Title: "Red planet", Text: "Which planet in our solar system is commonly called the Red Planet?", Topic: "science", Choices: []string{"Mars"}},
+	}
+	for i, q := range days {
+		h.llm.QueueChat(llmtest.QuestionReply(q))
+		_, body := h.generate(t)
+		answer := q.Choices[0]
+		if i == 1 {
+			answer = "K2"
+			h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: false, Reason: "K2 is second."}))
+		}
+		h.answerAs(t, token, body["id"].(string), answer)
+		h.now = h.now.Add(24 * time.Hour)
+	}
+	h.now = h.now.Add(-24 * time.Hour)
+
+	status, history := h.do(t, http.MethodGet, "/v1/me/history", nil, auth)
+	entries, _ := history["entries"].([]any)
+	if status != http.StatusOK || len(entries) != 3 {
+		t.Fatalf("history: %d %v", status, history)
+	}
+	latest := entries[0].(map[string]any)
+	if latest["date"] != "2026-10-18" || latest["answer"].(map[string]any)["score"] != float64(10) {
+		t.Fatalf("latest entry: %v", latest)
+	}
+	if missed := entries[1].(map[string]any)["answer"].(map[string]any); missed["feedback"] != "K2 is second." {
+		t.Fatalf("missed entry: %v", missed)
+	}
+
+	status, stats := h.do(t, http.MethodGet, "/v1/me/stats", nil, auth)
+	if status != http.StatusOK || stats["answered"] != float64(3) || stats["correct"] != float64(2) ||
+		stats["current_streak"] != float64(1) || stats["longest_streak"] != float64(1) {
+		t.Fatalf("stats: %d %v", status, stats)
+	}
+
+	if status, _ := h.do(t, http.MethodGet, "/v1/me/stats", nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("anonymous stats status = %d", status)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	h := newHarness(t)
+	alice, bob, carol := h.newPlayer(t), h.newPlayer(t), h.newPlayer(t)
+
+	h.now = time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	h.llm.QueueChat(llmtest.QuestionReply(capitalQuestion))
+	_, q1 := h.generate(t)
+	h.answerAs(t, carol, q1["id"].(string), "Paris")
+	h.now = h.now.Add(time.Hour)
+	h.answerAs(t, bob, q1["id"].(string), "Paris")
+	h.answerAs(t, alice, q1["id"].(string), "Paris")
+
+	h.now = time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	h.llm.QueueChat(llmtest.QuestionReply(llm.Question{Title: "Red planet", Text: "Which planet in our solar system is commonly called the Red Planet?", Topic: "science", Choices: []string{"Mars"}}))
+	_, q2 := h.generate(t)
+	h.answerAs(t, alice, q2["id"].(string), "Mars")
+	h.answerAs(t, bob, q2["id"].(string), "mars")
+
+	if status, body := h.do(t, http.MethodPost, "/v1/admin/leaderboard/refresh", nil, map[string]string{"X-CRON-KEY": cronKey}); status != http.StatusOK {
+		t.Fatalf("refresh: %d %v", status, body)
+	}
+	rank := func(period string) []string {
+		status, body := h.do(t, http.MethodGet, "/v1/leaderboard?period="+period, nil, nil)
+		if status != http.StatusOK {
+			t.Fatalf("%s: %d %v", period, status, body)
+		}
+		var ids []string
+		for _, e := range body["entries"].([]any) {
+			ids = append(ids, e.(map[string]any)["player_id"].(string))
+		}
+		return ids
+	}
+	id := func(token string) string { return strings.SplitN(token, ".", 2)[0] }
+
+	// alice and bob tie on correct answers and streak and speed; id breaks the tie.
+	first, second := id(alice), id(bob)
+	if second < first {
+		first, second = second, first
+	}
+	if got := rank("week"); strings.Join(got, ",") != strings.Join([]string{first, second, id(carol)}, ",") {
+		t.Fatalf("week ranking = %v", got)
+	}
+	if got := rank("day"); len(got) != 2 || got[0] != first {
+		t.Fatalf("day ranking = %v", got)
+	}
+	if status, _ := h.do(t, http.MethodGet, "/v1/leaderboard?period=year", nil, nil); status != http.StatusBadRequest {
+		t.Fatalf("invalid period status = %d", status)
+	}
+}
+
+func TestDisputes(t *testing.T) {
+	h := newHarness(t)
+	id := h.publishCapital(t)
+	alice, bob := h.newPlayer(t), h.newPlayer(t)
+	admin := map[string]string{"X-CRON-KEY": cronKey}
+
+	h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: false, Reason: "Lutetia is the Roman name."}))
+	_, first := h.answerAs(t, alice, id, "Lutetia")
+	_, second := h.answerAs(t, bob, id, "lutetia")
+	if first["score"] != float64(0) || second["score"] != float64(0) {
+		t.Fatalf("answers: %v %v", first, second)
+	}
+	_, accepted := h.answerAs(t, bob, id, "Paris")
+	path := func(answer map[string]any) string { return "/v1/answers/" + answer["id"].(string) + "/dispute" }
+	auth := func(token string) map[string]string { return map[string]string{"Authorization": "Bearer " + token} }
+	reason := map[string]string{"reason": "Lutetia is Paris."}
+
+	if status, body := h.do(t, http.MethodPost, path(first), reason, nil); status != http.StatusUnauthorized {
+		t.Fatalf("anonymous: %d %v", status, body)
+	}
+	if status, body := h.do(t, http.MethodPost, path(first), reason, auth(bob)); status != http.StatusNotFound {
+		t.Fatalf("other player's answer: %d %v", status, body)
+	}
+	if status, body := h.do(t, http.MethodPost, path(accepted), reason, auth(bob)); status != http.StatusConflict {
+		t.Fatalf("accepted answer: %d %v", status, body)
+	}
+	status, dispute := h.do(t, http.MethodPost, path(first), reason, auth(alice))
+	if status != http.StatusCreated || dispute["status"] != "open" {
+		t.Fatalf("dispute: %d %v", status, dispute)
+	}
+	if status, body := h.do(t, http.MethodPost, path(first), reason, auth(alice)); status != http.StatusConflict {
+		t.Fatalf("second dispute: %d %v", status, body)
+	}
+
+	if status, body := h.do(t, http.MethodGet, "/v1/admin/disputes", nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("list without key: %d %v", status, body)
+	}
+	status, list := h.do(t, http.MethodGet, "/v1/admin/disputes", nil, admin)
+	disputes, _ := list["disputes"].([]any)
+	if status != http.StatusOK || len(disputes) != 1 {
+		t.Fatalf("list: %d %v", status, list)
+	}
+	listed := disputes[0].(map[string]any)
+	if listed["reason"] != "Lutetia is Paris." || listed["answer"].(map[string]any)["text"] != "Lutetia" {
+		t.Fatalf("listed dispute: %v", listed)
+	}
+
+	resolve := "/v1/admin/disputes/" + dispute["id"].(string) + "/resolve"
+	status, resolved := h.do(t, http.MethodPost, resolve, map[string]any{"accept": true, "add_alias": true, "note": "Old name"}, admin)
+	if status != http.StatusOK || resolved["status"] != "accepted" || resolved["rescored"] != float64(1) {
+		t.Fatalf("resolve: %d %v", status, resolved)
+	}
+	answer := resolved["answer"].(map[string]any)
+	if answer["score"] != float64(10) || answer["provenance"].(map[string]any)["tier"] != "dispute" {
+		t.Fatalf("disputed answer: %v", answer)
+	}
+	if choices := resolved["question"].(map[string]any)["choices"].([]any); len(choices) != 3 || choices[2] != "Lutetia" {
+		t.Fatalf("choices: %v", choices)
+	}
+	if status, body := h.do(t, http.MethodPost, resolve, map[string]any{"accept": false}, admin); status != http.StatusConflict {
+		t.Fatalf("resolve twice: %d %v", status, body)
+	}
+
+	_, history := h.do(t, http.MethodGet, "/v1/me/history", nil, auth(bob))
+	entries, _ := history["entries"].([]any)
+	if len(entries) != 1 || entries[0].(map[string]any)["answer"].(map[string]any)["score"] != float64(10) {
+		t.Fatalf("rescored history: %v", history)
+	}
+	chats := h.llm.Count("/v1/chat/completions")
+	if status, body := h.answer(t, id, "LUTETIA"); status != http.StatusOK || body["score"] != float64(10) || body["tier"] != "exact" {
+		t.Fatalf("answer after alias: %d %v", status, body)
+	}
+	if h.llm.Count("/v1/chat/completions") != chats {
+		t.Fatalf("grader called for a new alias")
+	}
+}
+
+func TestRegrade(t *testing.T) {
+	h := newHarness(t)
+	id := h.publishCapital(t)
+	admin := map[string]string{"X-CRON-KEY": cronKey}
+
+	h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: false, Reason: "Not the modern name."}))
+	h.answer(t, id, "Lutetia")
+	h.answer(t, id, "lutetia")
+	h.answer(t, id, "Paris")
+
+	if status, body := h.do(t, http.MethodPost, "/v1/admin/regrade", map[string]any{"question_id": id}, nil); status != http.StatusUnauthorized {
+		t.Fatalf("without key: %d %v", status, body)
+	}
+	if status, body := h.do(t, http.MethodPost, "/v1/admin/regrade", map[string]any{"from": "2026-10-16"}, admin); status != http.StatusBadRequest {
+		t.Fatalf("open range: %d %v", status, body)
+	}
+	if status, body := h.do(t, http.MethodPost, "/v1/admin/regrade", map[string]any{"question_id": "00000000-0000-0000-0000-000000000000"}, admin); status != http.StatusNotFound {
+		t.Fatalf("unknown question: %d %v", status, body)
+	}
+
+	// The grader now accepts the Roman name; both answers share one call.
+	chats := h.llm.Count("/v1/chat/completions")
+	h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Paris", Reason: "Roman name of Paris."}))
+	status, dry := h.do(t, http.MethodPost, "/v1/admin/regrade", map[string]any{"question_id": id}, admin)
+	changes, _ := dry["changes"].([]any)
+	if status != http.StatusOK || dry["applied"] != false || dry["answers"] != float64(3) || len(changes) != 2 {
+		t.Fatalf("dry run: %d %v", status, dry)
+	}
+	if c := changes[1].(map[string]any); c["old_score"] != float64(0) || c["new_score"] != float64(10) || c["old_tier"] != "cache" || c["new_tier"] != "llm" {
+		t.Fatalf("change: %v", c)
+	}
+	if n := h.llm.Count("/v1/chat/completions") - chats; n != 1 {
+		t.Fatalf("grader calls = %d, want 1", n)
+	}
+	if _, body := h.answer(t, id, "LUTETIA"); body["score"] != float64(0) {
+		t.Fatalf("dry run changed the cached verdict: %v", body)
+	}
+
+	h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Paris", Reason: "Roman name of Paris."}))
+	status, applied := h.do(t, http.MethodPost, "/v1/admin/regrade", map[string]any{"from": "2026-10-16", "to": "2026-10-16", "apply": true}, admin)
+	if changes, _ := applied["changes"].([]any); status != http.StatusOK || applied["applied"] != true || applied["questions"] != float64(1) || len(changes) != 3 {
+		t.Fatalf("apply: %d %v", status, applied)
+	}
+	h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Paris", Reason: "Roman name of Paris."}))
+	if status, again := h.do(t, http.MethodPost, "/v1/admin/regrade", map[string]any{"question_id": id}, admin); status != http.StatusOK || again["failed"] != float64(0) || len(again["changes"].([]any)) != 0 {
+		t.Fatalf("second regrade: %d %v", status, again)
+	}
+	if _, body := h.answer(t, id, "Lutetia"); body["score"] != float64(10) || body["tier"] != "cache" {
+		t.Fatalf("answer after regrade: %v", body)
+	}
+}
+
+func TestAdminQuestions(t *testing.T) {
+	h := newHarness(t)
+	admin := map[string]string{"X-CRON-KEY": cronKey}
+	id := h.publishCapital(t)
+	moon := llm.Question{Title: "Moon landing", Text: "Which astronaut was the first person to walk on the Moon?", Topic: "history", Choices: []string{"Neil Armstrong", "Armstrong"}}
+	h.llm.QueueChat(llmtest.QuestionReply(moon))
+	if job := h.fillBacklog(t, 1); job["status"] != db.JobSucceeded {
+		t.Fatalf("fill: %v", job)
+	}
+
+	list := func(query string) (int, []any, map[string]any) {
+		t.Helper()
+		status, body := h.do(t, http.MethodGet, "/v1/admin/questions"+query, nil, admin)
+		questions, _ := body["questions"].([]any)
+		return status, questions, body
+	}
+	if status, body := h.do(t, http.MethodGet, "/v1/admin/questions", nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("without key: %d %v", status, body)
+	}
+	if status, questions, body := list(""); status != http.StatusOK || len(questions) != 2 || body["total"] != float64(2) || questions[0].(map[string]any)["topic"] != "history" {
+		t.Fatalf("list: %d %v", status, body)
+	}
+	if _, questions, body := list("?topic=Geography"); len(questions) != 1 || questions[0].(map[string]any)["id"] != id {
+		t.Fatalf("topic filter: %v", body)
+	}
+	if _, questions, body := list("?from=2026-10-16&to=2026-10-16"); len(questions) != 1 || questions[0].(map[string]any)["id"] != id {
+		t.Fatalf("date filter: %v", body)
+	}
+	if _, questions, body := list("?limit=1&offset=1"); len(questions) != 1 || body["total"] != float64(2) || questions[0].(map[string]any)["id"] != id {
+		t.Fatalf("second page: %v", body)
+	}
+	for _, query := range []string{"?limit=0", "?offset=-1", "?from=yesterday"} {
+		if status, _, body := list(query); status != http.StatusBadRequest {
+			t.Errorf("%s: %d %v", query, status, body)
+		}
+	}
+
+	path := "/v1/admin/questions/" + id
+	if status, q := h.do(t, http.MethodGet, path, nil, admin); status != http.StatusOK || q["title"] != capitalQuestion.Title {
+		t.Fatalf("get: %d %v", status, q)
+	}
+
+	embeds := h.llm.Count("/v1/embeddings")
+	if status, q := h.do(t, http.MethodPatch, path, map[string]any{"title": "French capital"}, admin); status != http.StatusOK || q["title"] != "French capital" || q["text"] != capitalQuestion.Text {
+		t.Fatalf("edit title: %d %v", status, q)
+	}
+	if n := h.llm.Count("/v1/embeddings"); n != embeds {
+		t.Fatalf("title edit embedded %d times", n-embeds)
+	}
+	text := "Which city has been the capital of the French state since the tenth century?"
+	if status, q := h.do(t, http.MethodPatch, path, map[string]any{"text": text}, admin); status != http.StatusOK || q["text"] != text {
+		t.Fatalf("edit text: %d %v", status, q)
+	}
+	if n := h.llm.Count("/v1/embeddings"); n != embeds+1 {
+		t.Fatalf("text edit embedded %d times, want 1", n-embeds)
+	}
+
+	// A reworded text is checked against the other questions, not its own.
+	h.llm.QueueEmbed(llmtest.Embedding(llmtest.Vector(moon.Text)))
+	if status, body := h.do(t, http.MethodPatch, path, map[string]any{"text": "Who was the first astronaut to set foot on the Moon?"}, admin); status != http.StatusConflict {
+		t.Fatalf("similar text: %d %v", status, body)
+	}
+	h.llm.QueueEmbed(llmtest.Embedding(llmtest.Vector(text)))
+	if status, body := h.do(t, http.MethodPatch, path, map[string]any{"text": "Which city has served as the capital of the French state since the tenth century?"}, admin); status != http.StatusOK {
+		t.Fatalf("own text: %d %v", status, body)
+	}
+
+	for name, tc := range map[string]struct {
+		body map[string]any
+		want int
+	}{
+		"short text":        {map[string]any{"text": "Paris?"}, http.StatusBadRequest},
+		"empty title":       {map[string]any{"title": " "}, http.StatusBadRequest},
+		"no choices":        {map[string]any{"choices": []string{}}, http.StatusBadRequest},
+		"duplicate text":    {map[string]any{"text": moon.Text}, http.StatusConflict},
+		"duplicate choices": {map[string]any{"choices": []string{"Armstrong", "Neil Armstrong"}}, http.StatusConflict},
+		"shared choice":     {map[string]any{"choices": []string{"Paris", "Armstrong"}}, http.StatusConflict},
+	} {
+		if status, body := h.do(t, http.MethodPatch, path, tc.body, admin); status != tc.want {
+			t.Errorf("%s: %d %v, want %d", name, status, body, tc.want)
+		}
+	}
+
+	status, q := h.do(t, http.MethodPatch, path, map[string]any{"choices": []string{"Paris", "Lutèce"}}, admin)
+	if choices, _ := q["choices"].([]any); status != http.StatusOK || len(choices) != 2 || choices[1] != "Lutèce" {
+		t.Fatalf("edit choices: %d %v", status, q)
+	}
+	if status, body := h.answer(t, id, "Lutece"); status != http.StatusOK || body["score"] != float64(10) || body["tier"] != "folded" {
+		t.Fatalf("answer after edit: %d %v", status, body)
+	}
+
+	if status, body := h.do(t, http.MethodDelete, path, nil, admin); status != http.StatusNoContent {
+		t.Fatalf("delete: %d %v", status, body)
+	}
+	if status, body := h.do(t, http.MethodGet, path, nil, admin); status != http.StatusNotFound {
+		t.Fatalf("get deleted: %d %v", status, body)
+	}
+	if status, body := h.do(t, http.MethodDelete, path, nil, admin); status != http.StatusNotFound {
+		t.Fatalf("delete twice: %d %v", status, body)
+	}
+	if status, body := h.answer(t, id, "Paris"); status != http.StatusNotFound {
+		t.Fatalf("answer deleted question: %d %v", status, body)
+	}
+}
+
+func TestReviewQueue(t *testing.T) {
+	h := newHarness(t, func(o *service.Options) { o.RequireReview = true })
+	admin := map[string]string{"X-CRON-KEY": cronKey}
+	h.llm.QueueChat(llmtest.QuestionReply(capitalQuestion))
+	status, draft := h.generate(t)
+	if status != http.StatusOK || draft["status"] != "draft" {
+		t.Fatalf("generate: %d %v", status, draft)
+	}
+	draftID := draft["id"].(string)
+	if status, body := h.do(t, http.MethodGet, "/v1/question/today", nil, nil); status != http.StatusNotFound {
+		t.Fatalf("today with a draft: %d %v", status, body)
+	}
+	if status, body := h.answer(t, draftID, "Paris"); status != http.StatusNotFound {
+		t.Fatalf("answer a draft: %d %v", status, body)
+	}
+
+	everest := llm.Question{Title: "Tallest", Text: "What is the name of the tallest mountain above sea level on Earth?", Topic: "geography", Choices: []string{"Everest"}}
+	h.llm.QueueChat(llmtest.QuestionReply(everest))
+	fill := h.fillBacklog(t, 1)
+	queuedID := fill["added"].([]any)[0].(map[string]any)["id"].(string)
+	_, list := h.do(t, http.MethodGet, "/v1/admin/questions?status=draft", nil, admin)
+	if list["total"] != float64(2) {
+		t.Fatalf("drafts: %v", list)
+	}
+
+	review := func(id, action string, body any) (int, map[string]any) {
+		return h.do(t, http.MethodPost, "/v1/admin/questions/"+id+"/"+action, body, admin)
+	}
+	if status, q := review(queuedID, "approve", nil); status != http.StatusOK || q["status"] != "approved" {
+		t.Fatalf("approve queued: %d %v", status, q)
+	}
+	// A draft dated today does not keep the backlog from filling the day.
+	status, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil)
+	if status != http.StatusOK || today["id"] != queuedID {
+		t.Fatalf("today beside a draft: %d %v", status, today)
+	}
+	if _, q := h.do(t, http.MethodGet, "/v1/admin/questions/"+queuedID, nil, admin); q["status"] != "published" {
+		t.Fatalf("promoted question: %v", q)
+	}
+	status, rejected := review(draftID, "reject", map[string]string{"note": "Paris was not the capital in 987."})
+	if status != http.StatusOK || rejected["status"] != "rejected" || rejected["review_note"] != "Paris was not the capital in 987." || rejected["publish_date"] != nil {
+		t.Fatalf("reject: %d %v", status, rejected)
+	}
+	if status, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil); status != http.StatusOK || today["id"] != queuedID {
+		t.Fatalf("today after review: %d %v", status, today)
+	}
+
+	for _, tc := range []struct{ id, action string }{{draftID, "approve"}, {queuedID, "reject"}, {queuedID, "approve"}} {
+		if status, body := review(tc.id, tc.action, nil); status != http.StatusConflict {
+			t.Errorf("%s %s: %d %v", tc.action, tc.id, status, body)
+		}
+	}
+	if status, body := review("00000000-0000-0000-0000-000000000000", "approve", nil); status != http.StatusNotFound {
+		t.Fatalf("unknown question: %d %v", status, body)
+	}
+}
+
+func TestReviewApprovesDatedDraft(t *testing.T) {
+	h := newHarness(t, func(o *service.Options) { o.RequireReview = true })
+	id := h.publishCapital(t)
+	if status, q := h.do(t, http.MethodPost, "/v1/admin/questions/"+id+"/approve", map[string]string{"note": "Checked."}, map[string]string{"X-CRON-KEY": cronKey}); status != http.StatusOK || q["status"] != "published" {
+		t.Fatalf("approve: %d %v", status, q)
+	}
+	if status, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil); status != http.StatusOK || today["id"] != id {
+		t.Fatalf("today: %d %v", status, today)
+	}
+}
+
+func TestReviewForcedRegenerate(t *testing.T) {
+	h := newHarness(t, func(o *service.Options) { o.RequireReview = true })
+	admin := map[string]string{"X-CRON-KEY": cronKey}
+	live := h.publishCapital(t)
+	if status, q := h.do(t, http.MethodPost, "/v1/admin/questions/"+live+"/approve", nil, admin); status != http.StatusOK || q["status"] != db.StatusPublished {
+		t.Fatalf("approve: %d %v", status, q)
+	}
+
+	h.llm.QueueChat(llmtest.QuestionReply(llm.Question{Title: "Tallest", Text: "What is the name of the tallest mountain above sea level on Earth?", Topic: "geography", Choices: []string{"Everest"}}))
+	status, draft := h.generate(t, "force=true")
+	if status != http.StatusOK || draft["status"] != db.StatusDraft || draft["publish_date"] != "2026-10-16" {
+		t.Fatalf("forced generate: %d %v", status, draft)
+	}
+	// The live question stays up until its replacement is approved.
+	if status, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil); status != http.StatusOK || today["id"] != live {
+		t.Fatalf("today before approval: %d %v", status, today)
+	}
+	if status, q := h.do(t, http.MethodPost, "/v1/admin/questions/"+draft["id"].(string)+"/approve", nil, admin); status != http.StatusOK || q["status"] != db.StatusPublished {
+		t.Fatalf("approve replacement: %d %v", status, q)
+	}
+	if status, today := h.do(t, http.MethodGet, "/v1/question/today", nil, nil); status != http.StatusOK || today["id"] != draft["id"] {
+		t.Fatalf("today after approval: %d %v", status, today)
+	}
+	if _, old := h.do(t, http.MethodGet, "/v1/admin/questions/"+live, nil, admin); old["status"] != db.StatusSuperseded || old["publish_date"] != "2026-10-16" {
+		t.Fatalf("replaced question: %v", old)
+	}
+}
+
+func TestGenerateVerification(t *testing.T) {
+	fake := llmtest.NewServer(t)
+	h := newHarnessWith(t, fake, func(o *service.Options) { o.Verifier = fake.Provider().Verifier })
+	wrong := llm.Question{Title: "Moon landing", Text: "In which year did Apollo 11 land on the Moon?", Topic: "history", Choices: []string{"1968"}, AnswerType: llm.AnswerYear}
+	right := llm.Question{Title: "Capital", Text: "What is the capital city of Australia?", Topic: "geography", Choices: []string{"Canberra"}}
+	h.llm.QueueChat(
+		llmtest.QuestionReply(wrong),
+		llmtest.AnswerReply("1969"),
+		llmtest.QuestionReply(right),
+		llmtest.AnswerReply("canberra."),
+	)
+
+	status, body := h.generate(t)
+	if status != http.StatusOK || body["text"] != right.Text {
+		t.Fatalf("generate: %d %v", status, body)
+	}
+	verification, _ := body["verification"].(map[string]any)
+	if verification["verdict"] != "passed" || verification["answer"] != "canberra." || verification["matched_choice"] != "Canberra" || verification["prompt_version"] != llm.VerifyPromptVersion {
+		t.Fatalf("verification: %v", body["verification"])
+	}
+	var chats []llmtest.Request
+	for _, r := range h.llm.Requests() {
+		if r.Path == "/v1/chat/completions" {
+			chats = append(chats, r)
+		}
+	}
+	if len(chats) != 4 {
+		t.Fatalf("chat calls: got %d, want 4", len(chats))
+	}
+	for _, r := range []llmtest.Request{chats[1], chats[3]} {
+		if r.Body["temperature"] != float64(0) {
+			t.Fatalf("blind answer temperature: %v", r.Body["temperature"])
+		}
+		if msgs, _ := json.Marshal(r.Body["messages"]); strings.Contains(string(msgs), "Canberra") {
+			t.Fatalf("blind answer saw the choices: %s", msgs)
+		}
+	}
+
+	id, _ := body["id"].(string)
+	status, q := h.do(t, http.MethodGet, "/v1/admin/questions/"+id, nil, map[string]string{"X-CRON-KEY": cronKey})
+	if stored, _ := q["verification"].(map[string]any); status != http.StatusOK || stored["model"] == nil {
+		t.Fatalf("stored verification: %d %v", status, q)
+	}
+}
+
+func TestPendingAnswers(t *testing.T) {
+	h := newHarness(t, func(o *service.Options) { o.GradeRetry = time.Minute })
+	id := h.publishCapital(t)
+	token := h.newPlayer(t)
+	auth := map[string]string{"Authorization": "Bearer " + token}
+	admin := map[string]string{"X-CRON-KEY": cronKey}
+
+	h.llm.QueueChat(llmtest.Status(http.StatusServiceUnavailable, `{"error":"overloaded"}`))
+	status, body := h.answerAs(t, token, id, "Lutetia")
+	answerID, _ := body["id"].(string)
+	if status != http.StatusAccepted || body["status"] != "pending" || answerID == "" || body["score"] != nil {
+		t.Fatalf("answer: %d %v", status, body)
+	}
+	if status, body := h.do(t, http.MethodGet, "/v1/answers/"+answerID, nil, auth); status != http.StatusOK || body["status"] != "pending" || body["score"] != nil {
+		t.Fatalf("poll pending: %d %v", status, body)
+	}
+	if status, _ := h.do(t, http.MethodGet, "/v1/answers/"+answerID, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("poll without token: got %d, want 404", status)
+	}
+	if status, _ := h.do(t, http.MethodPost, "/v1/answers/"+answerID+"/dispute", map[string]string{"reason": "slow"}, auth); status != http.StatusConflict {
+		t.Fatalf("dispute pending: got %d, want 409", status)
+	}
+	if status, stats := h.do(t, http.MethodGet, "/v1/me/stats", nil, auth); status != http.StatusOK || stats["answered"] != float64(0) {
+		t.Fatalf("stats: %d %v", status, stats)
+	}
+	if status, _ := h.answerAs(t, token, id, "Paris"); status != http.StatusOK {
+		t.Fatalf("second answer: got %d", status)
+	}
+
+	// Errors a retry cannot fix fail the request instead of waiting.
+	for name, replies := range map[string][]llmtest.Response{
+		"rejected request": {llmtest.Status(http.StatusBadRequest, `{"error":"invalid model"}`)},
+		"invalid json":     {llmtest.ChatContent("{\"match\": tru"), llmtest.ChatContent("not json")},
+	} {
+		h.llm.QueueChat(replies...)
+		if status, body := h.answer(t, id, "Berlin"); status != http.StatusInternalServerError {
+			t.Errorf("%s: %d %v, want 500", name, status, body)
+		}
+	}
+
+	// The grader is still down: the answer waits a minute before the next try.
+	h.llm.QueueChat(llmtest.Status(http.StatusServiceUnavailable, `{"error":"overloaded"}`))
+	if status, body := h.do(t, http.MethodPost, "/v1/admin/answers/grade-pending", nil, admin); status != http.StatusOK || body["graded"] != float64(0) {
+		t.Fatalf("first pass: %d %v", status, body)
+	}
+	chats := h.llm.Count("/v1/chat/completions")
+	if _, body := h.do(t, http.MethodPost, "/v1/admin/answers/grade-pending", nil, admin); body["graded"] != float64(0) || h.llm.Count("/v1/chat/completions") != chats {
+		t.Fatalf("answer retried before its backoff: %v", body)
+	}
+
+	h.now = h.now.Add(2 * time.Minute)
+	h.llm.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Paris", Reason: "Roman name of Paris."}))
+	if status, body := h.do(t, http.MethodPost, "/v1/admin/answers/grade-pending", nil, admin); status != http.StatusOK || body["graded"] != float64(1) {
+		t.Fatalf("second pass: %d %v", status, body)
+	}
+	status, body = h.do(t, http.MethodGet, "/v1/answers/"+answerID, nil, auth)
+	if status != http.StatusOK || body["status"] != "graded" || body["score"] != float64(10) || body["tier"] != "llm" || body["practice"] != false {
+		t.Fatalf("poll graded: %d %v", status, body)
+	}
+	if _, stats := h.do(t, http.MethodGet, "/v1/me/stats", nil, auth); stats["answered"] != float64(1) || stats["correct"] != float64(1) {
+		t.Fatalf("stats after grading: %v", stats)
+	}
+}
@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the OpenAI API root used when no base URL is configured.
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// ClientConfig configures an OpenAI-compatible client. BaseURL lets the
+// clients talk to Azure OpenAI, vLLM, LocalAI, Ollama or a test stand-in.
+type ClientConfig struct {
+	APIKey  string
+	Model   string
+	BaseURL string
+	Headers map[string]string
+	Timeout time.Duration
+	// Retry applies to rate limits, 5xx replies and network failures.
+	Retry RetryPolicy
+	// Breaker, when set, fails calls fast while the provider is down. Share
+	// one between the clients of a provider.
+	Breaker *Breaker
+	// StructuredOutput requests schema-constrained JSON (response_format
+	// json_schema) for chat completions. Leave it off for servers that do
+	// not support it.
+	StructuredOutput bool
+}
+
+type apiClient struct {
+	apiKey  string
+	baseURL string
+	headers map[string]string
+	http    *http.Client
+	retry   RetryPolicy
+	breaker *Breaker
+	// structured mirrors ClientConfig.StructuredOutput.
+	structured bool
+}
+
+func newAPIClient(cfg ClientConfig, defaultTimeout time.Duration) apiClient {
+	base := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	headers := make(map[string]string, len(cfg.Headers))
+	for k, v := range cfg.Headers {
+		headers[k] = v
+	}
+	return apiClient{apiKey: cfg.APIKey, baseURL: base, headers: headers, http: &http.Client{Timeout: timeout}, retry: cfg.Retry, breaker: cfg.Breaker, structured: cfg.StructuredOutput}
+}
+
+// post sends body as JSON to baseURL+path and returns the reply body.
+// Extra headers are applied last so they can override Authorization (e.g.
+// Azure's api-key header setups). Calls that fail with ErrUnavailable are
+// retried under the client's policy, and the breaker sees one outcome per
+// call.
+func (c apiClient) post(ctx context.Context, path string, body any) ([]byte, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	data, err := c.send(ctx, path, b)
+	for n := 1; err != nil; n++ {
+		wait, ok := c.retry.delay(n, err)
+		if !ok || sleep(ctx, wait) != nil {
+			break
+		}
+		data, err = c.send(ctx, path, b)
+	}
+	c.breaker.record(ctx, err)
+	return data, err
+}
+
+func (c apiClient) send(ctx context.Context, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &NetworkError{Err: err}
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &NetworkError{Err: err}
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(data), RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	}
+	return data, nil
+}
+
+// chat posts a chat completion request and returns the first choice's
+// content and the model that produced it. With structured output on, the
+// completion is constrained to schema, named name.
+func (c apiClient) chat(ctx context.Context, body map[string]any, name string, schema map[string]any) (content, model string, err error) {
+	if c.structured {
+		body["response_format"] = responseFormat(name, schema)
+	}
+	data, err := c.post(ctx, "/chat/completions", body)
+	if err != nil {
+		return "", "", err
+	}
+	var out struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", "", err
+	}
+	if len(out.Choices) == 0 {
+		return "", "", errors.New("no choices returned")
+	}
+	return out.Choices[0].Message.Content, out.Model, nil
+}
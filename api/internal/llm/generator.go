@@ -1,36 +1,70 @@
 package llm
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"io"
-	"net/http"
 	"time"
 )
 
-type Generator struct {
-	apiKey string
+// Format is how a question is presented to players.
+type Format string
+
+const (
+	FormatFreeText       Format = "free_text"
+	FormatMultipleChoice Format = "multiple_choice"
+)
+
+// AnswerType tells the grader how to read answers to a question.
+type AnswerType string
+
+const (
+	AnswerFreeText AnswerType = "free_text"
+	AnswerNumeric  AnswerType = "numeric"
+	AnswerYear     AnswerType = "year"
+	AnswerDate     AnswerType = "date"
+)
+
+type OpenAIGenerator struct {
 	model  string
-	client *http.Client
+	format Format
+	api    apiClient
 }
 
+// Question is a generated question. Choices are accepted aliases of the
+// correct answer. Multiple-choice questions also carry the canonical Correct
+// answer and wrong Distractors.
 type Question struct {
-	Title   string   `json:"title"`
-	Text    string   `json:"text"`
-	Topic   string   `json:"topic"`
-	Choices []string `json:"choices,omitempty"`
+	Title       string     `json:"title"`
+	Text        string     `json:"text"`
+	Topic       string     `json:"topic"`
+	Choices     []string   `json:"choices,omitempty"`
+	Correct     string     `json:"correct,omitempty"`
+	Distractors []string   `json:"distractors,omitempty"`
+	AnswerType  AnswerType `json:"answer_type,omitempty"`
+}
+
+func NewGenerator(cfg ClientConfig) *OpenAIGenerator {
+	return &OpenAIGenerator{model: cfg.Model, format: FormatFreeText, api: newAPIClient(cfg, 30*time.Second)}
 }
 
-func NewGenerator(apiKey, model string) *Generator {
-	return &Generator{apiKey: apiKey, model: model, client: &http.Client{Timeout: 30 * time.Second}}
+// WithFormat switches the kind of question the generator asks for.
+func (g *OpenAIGenerator) WithFormat(f Format) *OpenAIGenerator {
+	g.format = f
+	return g
 }
 
-func (g *Generator) GenerateQuestion(ctx context.Context) (Question, error) {
-	sys := `You generate a single factual trivia question as strict JSON. The question must be specific, factual, and verifiable (no opinions). Avoid yes/no. Question text length ~100-160 chars. Output ONLY strict JSON with fields: {"title", "text", "topic", "choices"}. The "choices" array must contain 1-5 direct aliases or exact surface forms for the correct answer. Each choice should be 1-3 words, contain no descriptions or roles (e.g., avoid "first female UK PM"), and only include valid synonyms, alternate spellings, or common epithets. Do not include any prose or Markdown.`
-	user := `Create a novel, accurate trivia question (history, science, geography, arts, or technology). Ensure the choices array contains only the explicit answer name and its close aliases; if no aliases exist, repeat the canonical name once.`
+const (
+	freeTextSystemPrompt = `You generate a single factual trivia question as strict JSON. The question must be specific, factual, and verifiable (no opinions). Avoid yes/no. Question text length ~100-160 chars. Output ONLY strict JSON with fields: {"title", "text", "topic", "answer_type", "choices"}. "answer_type" is "year" when the answer is a calendar year, "date" for a full calendar date, "numeric" for a quantity, otherwise "free_text"; for year, date and numeric answers the first choice must be the plain value (e.g. "1969", "1969-07-20", "8849 m"). The "choices" array must contain 1-5 direct aliases or exact surface forms for the correct answer. Each choice should be 1-3 words, contain no descriptions or roles (e.g., avoid "first female UK PM"), and only include valid synonyms, alternate spellings, or common epithets. Do not include any prose or Markdown.`
+	freeTextUserPrompt   = `Create a novel, accurate trivia question (history, science, geography, arts, or technology). Ensure the choices array contains only the explicit answer name and its close aliases; if no aliases exist, repeat the canonical name once.`
+
+	multipleChoiceSystemPrompt = `You generate a single factual multiple-choice trivia question as strict JSON. The question must be specific, factual, and verifiable (no opinions). Avoid yes/no. Question text length ~100-160 chars and must not list the options. Output ONLY strict JSON with fields: {"title", "text", "topic", "answer_type", "correct", "choices", "distractors"}. "answer_type" is "year", "date", "numeric" or "free_text" depending on the kind of answer. "correct" is the canonical correct answer (1-3 words). "choices" must contain 1-5 direct aliases or exact surface forms of the correct answer, including "correct". "distractors" must contain exactly 3 plausible but wrong answers of the same kind and similar length as "correct"; none may be an alias of the correct answer. Do not include any prose or Markdown.`
+	multipleChoiceUserPrompt   = `Create a novel, accurate multiple-choice trivia question (history, science, geography, arts, or technology). Make the distractors tempting for someone who half-remembers the answer.`
+)
+
+func (g *OpenAIGenerator) GenerateQuestion(ctx context.Context) (Question, error) {
+	sys, user := freeTextSystemPrompt, freeTextUserPrompt
+	if g.format == FormatMultipleChoice {
+		sys, user = multipleChoiceSystemPrompt, multipleChoiceUserPrompt
+	}
 	body := map[string]any{
 		"model":       g.model,
 		"temperature": 0.7,
@@ -39,35 +73,16 @@ func (g *Generator) GenerateQuestion(ctx context.Context) (Question, error) {
 			{"role": "user", "content": user},
 		},
 	}
-	b, _ := json.Marshal(body)
-	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/chat/completions", bytes.NewReader(b))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+g.apiKey)
-	resp, err := g.client.Do(req)
+	content, _, err := g.api.chat(ctx, body, "question", questionSchema(g.format))
 	if err != nil {
 		return Question{}, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode/100 != 2 {
-		data, _ := io.ReadAll(resp.Body)
-		return Question{}, fmt.Errorf("llm status %d: %s", resp.StatusCode, string(data))
-	}
-	var out struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return Question{}, err
-	}
-	if len(out.Choices) == 0 {
-		return Question{}, errors.New("no choices")
-	}
 	var q Question
-	if err := json.Unmarshal([]byte(extractJSON(out.Choices[0].Message.Content)), &q); err != nil {
+	if err := decodeOutput(content, g.api.structured, &q); err != nil {
 		return Question{}, err
 	}
+	if err := validateQuestion(q, g.format); err != nil {
+		return q, err
+	}
 	return q, nil
 }
@@ -5,45 +5,56 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
-	"net/http"
+	"strings"
 	"time"
 )
 
-type Grader struct {
-	apiKey string
-	model  string
-	client *http.Client
+type OpenAIGrader struct {
+	model string
+	api   apiClient
 }
 
+// GradePromptVersion identifies the grading prompt in stored provenance.
+// Bump it whenever the prompt changes.
+const GradePromptVersion = "grade-v1"
+
 type GradeResult struct {
 	Match  bool   `json:"match"`
 	Reason string `json:"reason"`
 	Choice string `json:"matched_choice"`
+
+	// Model, PromptVersion, Latency and Raw describe the call that produced
+	// the verdict. They are not part of the model's output.
+	Model         string        `json:"-"`
+	PromptVersion string        `json:"-"`
+	Latency       time.Duration `json:"-"`
+	Raw           string        `json:"-"`
 }
 
-func NewGrader(apiKey, model string) *Grader {
-	return &Grader{apiKey: apiKey, model: model, client: &http.Client{Timeout: 30 * time.Second}}
+func NewGrader(cfg ClientConfig) *OpenAIGrader {
+	return &OpenAIGrader{model: cfg.Model, api: newAPIClient(cfg, 30*time.Second)}
 }
 
-func (g *Grader) Grade(ctx context.Context, answer string, choices []string) (GradeResult, error) {
+func (g *OpenAIGrader) Grade(ctx context.Context, answer string, choices []string) (GradeResult, error) {
 	if len(choices) == 0 {
 		return GradeResult{Match: false, Reason: "no choices configured"}, nil
 	}
 	payload := g.buildPayload(answer, choices)
+	start := time.Now()
 	res, err := g.call(ctx, payload)
-	if err == nil {
-		return res, nil
+	if retryable(err) {
+		res, err = g.call(ctx, payload)
 	}
-	var parseErr *json.SyntaxError
-	if errors.As(err, &parseErr) || errors.Is(err, io.ErrUnexpectedEOF) {
-		return g.call(ctx, payload)
+	if err != nil {
+		return GradeResult{}, err
 	}
-	return GradeResult{}, err
+	res.PromptVersion = GradePromptVersion
+	res.Latency = time.Since(start)
+	return res, nil
 }
 
-func (g *Grader) buildPayload(answer string, choices []string) map[string]any {
+func (g *OpenAIGrader) buildPayload(answer string, choices []string) map[string]any {
 	sys := "You verify if a response matches any exact item in a provided list of acceptable answers. Only acknowledge exact equivalence, never approximate matches. Return strict JSON only."
 	user := map[string]any{
 		"answer":       answer,
@@ -65,41 +76,55 @@ func (g *Grader) buildPayload(answer string, choices []string) map[string]any {
 	}
 }
 
-func (g *Grader) call(ctx context.Context, body map[string]any) (GradeResult, error) {
-	b, _ := json.Marshal(body)
-	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/chat/completions", bytes.NewReader(b))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+g.apiKey)
-	resp, err := g.client.Do(req)
+func (g *OpenAIGrader) call(ctx context.Context, body map[string]any) (GradeResult, error) {
+	content, model, err := g.api.chat(ctx, body, "grade", gradeSchema)
 	if err != nil {
 		return GradeResult{}, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode/100 != 2 {
-		data, _ := io.ReadAll(resp.Body)
-		return GradeResult{}, fmt.Errorf("llm status %d: %s", resp.StatusCode, string(data))
-	}
 	var out struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
+		Match  *bool   `json:"match"`
+		Choice string  `json:"matched_choice"`
+		Reason *string `json:"reason"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+	if err := decodeOutput(content, g.api.structured, &out); err != nil {
 		return GradeResult{}, err
 	}
-	if len(out.Choices) == 0 {
-		return GradeResult{}, errors.New("no choices returned")
-	}
-	content := out.Choices[0].Message.Content
-	var gr GradeResult
-	if err := json.Unmarshal([]byte(extractJSON(content)), &gr); err != nil {
+	if err := validateGrade(out.Match, out.Choice, out.Reason); err != nil {
 		return GradeResult{}, err
 	}
+	gr := GradeResult{Match: *out.Match, Choice: out.Choice, Reason: *out.Reason, Model: model, Raw: content}
+	if gr.Model == "" {
+		gr.Model = g.model
+	}
 	return gr, nil
 }
 
+// validateGrade checks the fields every verdict needs: a match flag, a
+// reason, and the matched choice when it is a match.
+func validateGrade(match *bool, choice string, reason *string) error {
+	invalid := func(field, why string) error {
+		return &ValidationError{Output: "grade", Field: field, Reason: why}
+	}
+	switch {
+	case match == nil:
+		return invalid("match", "is required")
+	case reason == nil:
+		return invalid("reason", "is required")
+	case *match && strings.TrimSpace(choice) == "":
+		return invalid("matched_choice", "is required when match is true")
+	}
+	return nil
+}
+
+// retryable reports whether a completion was malformed or failed validation,
+// which a second call usually fixes.
+func retryable(err error) bool {
+	var parseErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var invalid *ValidationError
+	return errors.As(err, &parseErr) || errors.As(err, &typeErr) || errors.As(err, &invalid) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
 func mustJSON(v any) string {
 	b, _ := json.Marshal(v)
 	return string(b)
@@ -0,0 +1,249 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"qotd/api/internal/llm"
+	"qotd/api/internal/llm/llmtest"
+)
+
+func TestGradeRetriesOnceOnMalformedJSON(t *testing.T) {
+	srv := llmtest.NewServer(t)
+	srv.QueueChat(
+		llmtest.ChatContent("not json at all"),
+		llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Paris", Reason: "same city"}),
+	)
+	g := srv.Provider().Grader
+	res, err := g.Grade(context.Background(), "paris france", []string{"Paris"})
+	if err != nil {
+		t.Fatalf("grade: %v", err)
+	}
+	if !res.Match || res.Choice != "Paris" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res.Model != "fake-model" || res.PromptVersion != llm.GradePromptVersion || !strings.Contains(res.Raw, `"matched_choice":"Paris"`) {
+		t.Fatalf("provenance = model %q prompt %q raw %q", res.Model, res.PromptVersion, res.Raw)
+	}
+	if n := srv.Count("/v1/chat/completions"); n != 2 {
+		t.Fatalf("chat calls = %d, want 2", n)
+	}
+}
+
+func TestGradeErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		resp llmtest.Response
+		want string
+	}{
+		{"status", llmtest.Status(http.StatusBadGateway, `{"error":"upstream"}`), "llm status 502"},
+		{"empty choices", llmtest.EmptyChoices(), "no choices returned"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := llmtest.NewServer(t)
+			srv.QueueChat(c.resp)
+			_, err := srv.Provider().Grader.Grade(context.Background(), "x", []string{"y"})
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("err = %v, want containing %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestGenerateQuestionSendsConfiguredHeaders(t *testing.T) {
+	srv := llmtest.NewServer(t)
+	srv.QueueChat(llmtest.QuestionReply(llm.Question{Title: "T", Text: "Which letter comes first?", Topic: "x", Choices: []string{"A"}}))
+	gen := llm.NewGenerator(llm.ClientConfig{BaseURL: srv.BaseURL() + "/", Model: "m", Headers: map[string]string{"X-Tenant": "qotd"}})
+	q, err := gen.GenerateQuestion(context.Background())
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if q.Title != "T" || len(q.Choices) != 1 {
+		t.Fatalf("unexpected question: %+v", q)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 1 || reqs[0].Body["model"] != "m" {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+	if got := reqs[0].Header.Get("X-Tenant"); got != "qotd" {
+		t.Fatalf("X-Tenant = %q", got)
+	}
+	if got := reqs[0].Header.Get("Authorization"); got != "" {
+		t.Fatalf("Authorization sent without api key: %q", got)
+	}
+}
+
+func TestStructuredOutput(t *testing.T) {
+	srv := llmtest.NewServer(t)
+	srv.QueueChat(
+		llmtest.GradeReply(llm.GradeResult{Match: true, Reason: "same city"}),
+		llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Paris", Reason: "same city"}),
+	)
+	res, err := srv.Provider().Grader.Grade(context.Background(), "paris france", []string{"Paris"})
+	if err != nil || res.Choice != "Paris" {
+		t.Fatalf("grade = %+v, %v", res, err)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("chat calls = %d, want 2 (invalid verdict retried)", len(reqs))
+	}
+	format, _ := reqs[0].Body["response_format"].(map[string]any)
+	schema, _ := format["json_schema"].(map[string]any)
+	if format["type"] != "json_schema" || schema["name"] != "grade" || schema["strict"] != true {
+		t.Fatalf("response_format = %v", reqs[0].Body["response_format"])
+	}
+
+	srv.QueueChat(
+		llmtest.GradeReply(llm.GradeResult{Match: true, Reason: "same city"}),
+		llmtest.GradeReply(llm.GradeResult{Match: true, Reason: "same city"}),
+	)
+	_, err = srv.Provider().Grader.Grade(context.Background(), "paris france", []string{"Paris"})
+	var invalid *llm.ValidationError
+	if !errors.As(err, &invalid) || invalid.Output != "grade" || invalid.Field != "matched_choice" {
+		t.Fatalf("err = %v, want a grade validation error", err)
+	}
+}
+
+func TestGenerateQuestionValidation(t *testing.T) {
+	valid := llm.Question{Title: "Capital", Text: "What is the capital city of Australia?", Topic: "geography", Choices: []string{"Canberra"}}
+	cases := []struct {
+		name  string
+		edit  func(q *llm.Question)
+		field string
+	}{
+		{"missing title", func(q *llm.Question) { q.Title = "" }, "title"},
+		{"short text", func(q *llm.Question) { q.Text = "Capital?" }, "text"},
+		{"no choices", func(q *llm.Question) { q.Choices = nil }, "choices"},
+		{"long choice", func(q *llm.Question) { q.Choices = []string{"Canberra", "the capital of the Commonwealth"} }, "choices[1]"},
+		{"unknown answer type", func(q *llm.Question) { q.AnswerType = "city" }, "answer_type"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := llmtest.NewServer(t)
+			q := valid
+			c.edit(&q)
+			srv.QueueChat(llmtest.QuestionReply(q))
+			_, err := srv.Provider().Generator.GenerateQuestion(context.Background())
+			var invalid *llm.ValidationError
+			if !errors.As(err, &invalid) || invalid.Field != c.field {
+				t.Fatalf("err = %v, want a validation error on %s", err, c.field)
+			}
+		})
+	}
+
+	srv := llmtest.NewServer(t)
+	srv.QueueChat(llmtest.ChatContent("Here you go: " + mustJSON(valid)))
+	gen := llm.NewGenerator(llm.ClientConfig{BaseURL: srv.BaseURL(), Model: "m"})
+	if q, err := gen.GenerateQuestion(context.Background()); err != nil || q.Text != valid.Text {
+		t.Fatalf("unstructured generate = %+v, %v", q, err)
+	}
+	if _, ok := srv.Requests()[0].Body["response_format"]; ok {
+		t.Fatalf("response_format sent with structured output off")
+	}
+}
+
+func mustJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := llm.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	grader := func(srv *llmtest.Server) llm.Grader {
+		return llm.NewGrader(llm.ClientConfig{BaseURL: srv.BaseURL(), Model: "m", Retry: policy})
+	}
+	paris := llmtest.GradeReply(llm.GradeResult{Match: true, Choice: "Paris", Reason: "same city"})
+
+	t.Run("retries rate limits and 5xx", func(t *testing.T) {
+		srv := llmtest.NewServer(t)
+		srv.QueueChat(llmtest.Status(http.StatusServiceUnavailable, "{}"), llmtest.RateLimited("0"), paris)
+		if _, err := grader(srv).Grade(context.Background(), "paris france", []string{"Paris"}); err != nil {
+			t.Fatalf("grade: %v", err)
+		}
+		if n := srv.Count("/v1/chat/completions"); n != 3 {
+			t.Fatalf("chat calls = %d, want 3", n)
+		}
+	})
+
+	t.Run("gives up when Retry-After exceeds the cap", func(t *testing.T) {
+		srv := llmtest.NewServer(t)
+		srv.QueueChat(llmtest.RateLimited("120"), paris)
+		_, err := grader(srv).Grade(context.Background(), "paris france", []string{"Paris"})
+		var status *llm.StatusError
+		if !errors.As(err, &status) || status.RetryAfter != 2*time.Minute || !errors.Is(err, llm.ErrUnavailable) {
+			t.Fatalf("err = %v, want an unavailable 429 asking for 2m", err)
+		}
+		if n := srv.Count("/v1/chat/completions"); n != 1 {
+			t.Fatalf("chat calls = %d, want 1", n)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		srv := llmtest.NewServer(t)
+		srv.QueueChat(llmtest.Status(http.StatusBadRequest, `{"error":"bad model"}`), paris)
+		_, err := grader(srv).Grade(context.Background(), "paris france", []string{"Paris"})
+		if err == nil || errors.Is(err, llm.ErrUnavailable) {
+			t.Fatalf("err = %v, want a permanent error", err)
+		}
+		if n := srv.Count("/v1/chat/completions"); n != 1 {
+			t.Fatalf("chat calls = %d, want 1", n)
+		}
+	})
+}
+
+func TestBreaker(t *testing.T) {
+	srv := llmtest.NewServer(t)
+	breaker := llm.NewBreaker(2, 50*time.Millisecond)
+	cfg := llm.ClientConfig{BaseURL: srv.BaseURL(), Model: "m", Breaker: breaker}
+	grader, embedder := llm.NewGrader(cfg), llm.NewEmbedder(cfg)
+	ctx := context.Background()
+
+	srv.QueueChat(llmtest.Status(http.StatusBadGateway, "{}"), llmtest.Status(http.StatusBadGateway, "{}"))
+	for i := 0; i < 2; i++ {
+		if _, err := grader.Grade(ctx, "x", []string{"y"}); !errors.Is(err, llm.ErrUnavailable) {
+			t.Fatalf("call %d: err = %v", i, err)
+		}
+	}
+	if !breaker.Open() {
+		t.Fatalf("breaker closed after 2 failures")
+	}
+	// The circuit is shared, so the embedder fails fast too.
+	if _, err := embedder.Embed(ctx, "x"); !errors.Is(err, llm.ErrCircuitOpen) {
+		t.Fatalf("embed err = %v, want circuit open", err)
+	}
+	if n := len(srv.Requests()); n != 2 {
+		t.Fatalf("requests = %d, want 2", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	srv.QueueChat(llmtest.GradeReply(llm.GradeResult{Match: false, Reason: "no"}))
+	if _, err := grader.Grade(ctx, "x", []string{"y"}); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if breaker.Open() {
+		t.Fatalf("breaker still open after a successful probe")
+	}
+}
+
+func TestEmbedDeterministic(t *testing.T) {
+	srv := llmtest.NewServer(t)
+	e := srv.Provider().Embedder
+	a, err := e.Embed(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	b, _ := e.Embed(context.Background(), "hello")
+	if len(a) != llmtest.Dimensions || a[0] != b[0] || a[len(a)-1] != b[len(b)-1] {
+		t.Fatalf("embedding not deterministic")
+	}
+	srv.QueueEmbed(llmtest.Status(http.StatusTooManyRequests, "{}"))
+	if _, err := e.Embed(context.Background(), "hello"); err == nil {
+		t.Fatalf("expected error on 429")
+	}
+}
@@ -0,0 +1,235 @@
+// Package llmtest provides a deterministic, scriptable stand-in for an
+// OpenAI-compatible API (chat completions and embeddings) for tests.
+package llmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"qotd/api/internal/llm"
+)
+
+// Dimensions matches the pgvector column width used by the questions table.
+const Dimensions = 1536
+
+// Response is a scripted HTTP reply.
+type Response struct {
+	Status int
+	Body   string
+	Header http.Header
+}
+
+// Request records a call received by the server.
+type Request struct {
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// Server serves /v1/chat/completions and /v1/embeddings from scripted queues.
+// Chat calls with an empty queue fail with 500 so unscripted calls are obvious;
+// embedding calls with an empty queue return Vector(input).
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	chat     []Response
+	embed    []Response
+	requests []Request
+}
+
+// NewServer starts a server that is closed when the test finishes.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/chat/completions", s.handleChat)
+	mux.HandleFunc("/v1/embeddings", s.handleEmbed)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// BaseURL is the value to pass as llm.ClientConfig.BaseURL.
+func (s *Server) BaseURL() string { return s.URL + "/v1" }
+
+// Provider builds OpenAI clients pointed at the server, with structured
+// output on.
+func (s *Server) Provider() llm.Provider {
+	cfg := llm.ClientConfig{BaseURL: s.BaseURL(), Model: "fake-model", StructuredOutput: true}
+	generator := llm.NewGenerator(cfg)
+	return llm.Provider{
+		Grader:    llm.NewGrader(cfg),
+		Embedder:  llm.NewEmbedder(cfg),
+		Generator: generator,
+		Verifier:  generator,
+	}
+}
+
+// QueueChat appends responses for upcoming chat completion calls.
+func (s *Server) QueueChat(rs ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chat = append(s.chat, rs...)
+}
+
+// QueueEmbed appends responses for upcoming embedding calls.
+func (s *Server) QueueEmbed(rs ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.embed = append(s.embed, rs...)
+}
+
+// Requests returns every request received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Count returns how many requests hit path (e.g. "/v1/chat/completions").
+func (s *Server) Count(path string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+// Pending returns how many scripted chat responses have not been consumed.
+func (s *Server) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.chat)
+}
+
+func (s *Server) record(r *http.Request) map[string]any {
+	var body map[string]any
+	data, _ := io.ReadAll(r.Body)
+	_ = json.Unmarshal(data, &body)
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+	s.mu.Unlock()
+	return body
+}
+
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	s.record(r)
+	s.mu.Lock()
+	var resp Response
+	if len(s.chat) > 0 {
+		resp = s.chat[0]
+		s.chat = s.chat[1:]
+	} else {
+		resp = Status(http.StatusInternalServerError, `{"error":"no scripted chat response"}`)
+	}
+	s.mu.Unlock()
+	write(w, resp)
+}
+
+func (s *Server) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	body := s.record(r)
+	s.mu.Lock()
+	var resp Response
+	scripted := len(s.embed) > 0
+	if scripted {
+		resp = s.embed[0]
+		s.embed = s.embed[1:]
+	}
+	s.mu.Unlock()
+	if !scripted {
+		input, _ := body["input"].(string)
+		resp = Embedding(Vector(input))
+	}
+	write(w, resp)
+}
+
+func write(w http.ResponseWriter, resp Response) {
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	for k, vs := range resp.Header {
+		w.Header()[k] = vs
+	}
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, resp.Body)
+}
+
+// Status returns a raw reply with the given status code.
+func Status(code int, body string) Response { return Response{Status: code, Body: body} }
+
+// RateLimited is a 429 reply carrying a Retry-After header.
+func RateLimited(retryAfter string) Response {
+	return Response{Status: http.StatusTooManyRequests, Body: `{"error":"rate limited"}`, Header: http.Header{"Retry-After": {retryAfter}}}
+}
+
+// ChatContent wraps content as the first choice of a chat completion.
+func ChatContent(content string) Response {
+	body := map[string]any{
+		"choices": []map[string]any{
+			{"message": map[string]any{"role": "assistant", "content": content}},
+		},
+	}
+	return Response{Status: http.StatusOK, Body: mustJSON(body)}
+}
+
+// EmptyChoices is a 200 chat completion without any choices.
+func EmptyChoices() Response { return Response{Status: http.StatusOK, Body: `{"choices":[]}`} }
+
+// QuestionReply is a chat completion whose content is q as JSON.
+func QuestionReply(q llm.Question) Response { return ChatContent(mustJSON(q)) }
+
+// AnswerReply is a chat completion answering a question blind.
+func AnswerReply(answer string) Response {
+	return ChatContent(mustJSON(map[string]string{"answer": answer}))
+}
+
+// GradeReply is a chat completion whose content is g as JSON.
+func GradeReply(g llm.GradeResult) Response { return ChatContent(mustJSON(g)) }
+
+// Embedding is an embeddings reply carrying vec.
+func Embedding(vec []float32) Response {
+	return Response{Status: http.StatusOK, Body: mustJSON(map[string]any{"data": []map[string]any{{"embedding": vec}}})}
+}
+
+// Vector derives a unit vector from input. The same input always yields the
+// same vector and different inputs are close to orthogonal.
+func Vector(input string) []float32 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(input))
+	state := h.Sum64() | 1
+	vec := make([]float32, Dimensions)
+	var norm float64
+	for i := range vec {
+		state ^= state << 13
+		state ^= state >> 7
+		state ^= state << 17
+		v := float64(int64(state%2001)-1000) / 1000
+		vec[i] = float32(v)
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] = float32(float64(vec[i]) / norm)
+	}
+	return vec
+}
+
+func mustJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("llmtest: marshal: %v", err))
+	}
+	return string(b)
+}
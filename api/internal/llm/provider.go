@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Grader decides whether a free-form answer matches one of the accepted choices.
+type Grader interface {
+	Grade(ctx context.Context, answer string, choices []string) (GradeResult, error)
+}
+
+// Embedder turns text into a vector used for similarity checks.
+type Embedder interface {
+	Embed(ctx context.Context, input string) ([]float32, error)
+}
+
+// QuestionGenerator produces a new trivia question candidate. A candidate
+// failing validation is returned along with its *ValidationError.
+type QuestionGenerator interface {
+	GenerateQuestion(ctx context.Context) (Question, error)
+}
+
+// Verifier answers a generated question blind, without its choices.
+type Verifier interface {
+	AnswerQuestion(ctx context.Context, text string) (BlindAnswer, error)
+}
+
+// ProviderConfig carries the settings a provider factory needs to build its clients.
+// Zero timeouts fall back to each client's default.
+type ProviderConfig struct {
+	APIKey          string
+	BaseURL         string
+	Headers         map[string]string
+	EmbedModel      string
+	GradeModel      string
+	EmbedTimeout    time.Duration
+	GradeTimeout    time.Duration
+	GenerateTimeout time.Duration
+	// QuestionFormat defaults to FormatFreeText.
+	QuestionFormat Format
+	// StructuredOutput applies to the grader and generator; see
+	// ClientConfig.StructuredOutput.
+	StructuredOutput bool
+	// Retry applies to every client.
+	Retry RetryPolicy
+	// BreakerThreshold consecutive unavailable calls open a circuit shared
+	// by the provider's clients for BreakerCooldown; zero disables it.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+func (c ProviderConfig) client(model string, timeout time.Duration, breaker *Breaker) ClientConfig {
+	return ClientConfig{APIKey: c.APIKey, Model: model, BaseURL: c.BaseURL, Headers: c.Headers, Timeout: timeout, Retry: c.Retry, Breaker: breaker, StructuredOutput: c.StructuredOutput}
+}
+
+func (c ProviderConfig) format() Format {
+	if c.QuestionFormat == "" {
+		return FormatFreeText
+	}
+	return c.QuestionFormat
+}
+
+// Provider bundles the clients used by the question service.
+type Provider struct {
+	Grader    Grader
+	Embedder  Embedder
+	Generator QuestionGenerator
+	Verifier  Verifier
+}
+
+// ProviderFactory builds a Provider from config.
+type ProviderFactory func(cfg ProviderConfig) (Provider, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFactory{}
+)
+
+// RegisterProvider makes a provider available by name. Registering the same
+// name twice replaces the earlier factory.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[strings.ToLower(name)] = factory
+}
+
+// NewProvider builds the named provider.
+func NewProvider(name string, cfg ProviderConfig) (Provider, error) {
+	providersMu.RLock()
+	factory, ok := providers[strings.ToLower(name)]
+	providersMu.RUnlock()
+	if !ok {
+		return Provider{}, fmt.Errorf("unknown llm provider %q (available: %s)", name, strings.Join(ProviderNames(), ", "))
+	}
+	return factory(cfg)
+}
+
+// ProviderNames lists registered providers in sorted order.
+func ProviderNames() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterProvider("openai", func(cfg ProviderConfig) (Provider, error) {
+		breaker := NewBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown)
+		generator := NewGenerator(cfg.client(cfg.GradeModel, cfg.GenerateTimeout, breaker)).WithFormat(cfg.format())
+		return Provider{
+			Grader:    NewGrader(cfg.client(cfg.GradeModel, cfg.GradeTimeout, breaker)),
+			Embedder:  NewEmbedder(cfg.client(cfg.EmbedModel, cfg.EmbedTimeout, breaker)),
+			Generator: generator,
+			Verifier:  generator,
+		}, nil
+	})
+}
@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Limits checked on every generated question, whether or not the provider
+// enforced the schema.
+const (
+	MinQuestionText = 20
+	MaxQuestionText = 400
+	MaxChoices      = 5
+	// MaxChoiceWords leaves one word of slack over the prompt's 1-3 for
+	// names such as "Republic of the Congo".
+	MaxChoiceWords = 4
+	Distractors    = 3
+)
+
+// ValidationError reports a completion that decoded but does not match the
+// output schema, such as a question without choices or a verdict without a
+// reason.
+type ValidationError struct {
+	// Output is "question", "grade" or "answer".
+	Output string
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s output: %s %s", e.Output, e.Field, e.Reason)
+}
+
+// responseFormat asks the provider to constrain the completion to schema.
+func responseFormat(name string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   name,
+			"strict": true,
+			"schema": schema,
+		},
+	}
+}
+
+// decodeOutput unmarshals a completion into v. Schema-constrained content is
+// decoded as is; otherwise the JSON object is sliced out of any surrounding
+// prose first.
+func decodeOutput(content string, structured bool, v any) error {
+	if !structured {
+		content = extractJSON(content)
+	}
+	return json.Unmarshal([]byte(content), v)
+}
+
+func object(properties map[string]any) map[string]any {
+	required := make([]string, 0, len(properties))
+	for name := range properties {
+		required = append(required, name)
+	}
+	sort.Strings(required)
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+var (
+	stringSchema      = map[string]any{"type": "string"}
+	stringArraySchema = map[string]any{"type": "array", "items": stringSchema}
+)
+
+// questionSchema describes the generator's output for format f. Strict mode
+// requires every property, so lengths and counts are left to validateQuestion.
+func questionSchema(f Format) map[string]any {
+	props := map[string]any{
+		"title":       stringSchema,
+		"text":        stringSchema,
+		"topic":       stringSchema,
+		"answer_type": map[string]any{"type": "string", "enum": []string{string(AnswerFreeText), string(AnswerNumeric), string(AnswerYear), string(AnswerDate)}},
+		"choices":     stringArraySchema,
+	}
+	if f == FormatMultipleChoice {
+		props["correct"] = stringSchema
+		props["distractors"] = stringArraySchema
+	}
+	return object(props)
+}
+
+var (
+	gradeSchema = object(map[string]any{
+		"match":          map[string]any{"type": "boolean"},
+		"matched_choice": stringSchema,
+		"reason":         stringSchema,
+	})
+	answerSchema = object(map[string]any{"answer": stringSchema})
+)
+
+// validateQuestion checks a decoded question against the limits above.
+func validateQuestion(q Question, f Format) error {
+	if strings.TrimSpace(q.Title) == "" {
+		return invalidQuestion("title", "is required")
+	}
+	if strings.TrimSpace(q.Topic) == "" {
+		return invalidQuestion("topic", "is required")
+	}
+	if n := len(q.Text); n < MinQuestionText || n > MaxQuestionText {
+		return invalidQuestion("text", "is %d bytes, want %d to %d", n, MinQuestionText, MaxQuestionText)
+	}
+	switch q.AnswerType {
+	case "", AnswerFreeText, AnswerNumeric, AnswerYear, AnswerDate:
+	default:
+		return invalidQuestion("answer_type", "%q is not a known answer type", q.AnswerType)
+	}
+	// A multiple-choice question's correct answer counts as one of its
+	// choices.
+	if (len(q.Choices) == 0 && f != FormatMultipleChoice) || len(q.Choices) > MaxChoices {
+		return invalidQuestion("choices", "has %d entries, want 1 to %d", len(q.Choices), MaxChoices)
+	}
+	if err := checkWords("choices", q.Choices); err != nil {
+		return err
+	}
+	if f != FormatMultipleChoice {
+		return nil
+	}
+	if n := len(strings.Fields(q.Correct)); n == 0 || n > MaxChoiceWords {
+		return invalidQuestion("correct", "has %d words, want 1 to %d", n, MaxChoiceWords)
+	}
+	if len(q.Distractors) != Distractors {
+		return invalidQuestion("distractors", "has %d entries, want %d", len(q.Distractors), Distractors)
+	}
+	return checkWords("distractors", q.Distractors)
+}
+
+func checkWords(field string, values []string) error {
+	for i, v := range values {
+		if n := len(strings.Fields(v)); n == 0 || n > MaxChoiceWords {
+			return invalidQuestion(fmt.Sprintf("%s[%d]", field, i), "has %d words, want 1 to %d", n, MaxChoiceWords)
+		}
+	}
+	return nil
+}
+
+func invalidQuestion(field, reason string, args ...any) error {
+	return &ValidationError{Output: "question", Field: field, Reason: fmt.Sprintf(reason, args...)}
+}
@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable matches errors that mean the provider could not serve a
+// call right now: rate limits, 5xx replies, network failures and an open
+// circuit. Retrying later may succeed.
+var ErrUnavailable = errors.New("llm provider unavailable")
+
+// ErrCircuitOpen is returned without calling the provider while the
+// circuit breaker is open.
+var ErrCircuitOpen = fmt.Errorf("%w: circuit open", ErrUnavailable)
+
+// StatusError is a non-2xx reply. 429 and 5xx replies match ErrUnavailable.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is the delay the provider asked for, if any.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("llm status %d: %s", e.StatusCode, e.Body)
+}
+
+func (e *StatusError) Is(target error) bool {
+	return target == ErrUnavailable && e.temporary()
+}
+
+func (e *StatusError) temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// NetworkError is a call that got no reply, including client timeouts. It
+// matches ErrUnavailable.
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string { return "llm request failed: " + e.Err.Error() }
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+func (e *NetworkError) Is(target error) bool { return target == ErrUnavailable }
+
+// RetryPolicy retries calls that fail with ErrUnavailable, waiting a random
+// delay of up to BaseDelay doubled per attempt and capped at MaxDelay, or
+// the reply's Retry-After when it gives one. The zero value tries once.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// delay returns how long to wait before retry n (1 for the first retry),
+// and false when err should not be retried.
+func (p RetryPolicy) delay(n int, err error) (time.Duration, bool) {
+	if n >= p.MaxAttempts || !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) {
+		return 0, false
+	}
+	var status *StatusError
+	if errors.As(err, &status) && status.RetryAfter > 0 {
+		// Waiting less than asked only earns another 429; waiting longer
+		// than the cap holds the caller too long.
+		if p.MaxDelay > 0 && status.RetryAfter > p.MaxDelay {
+			return 0, false
+		}
+		return status.RetryAfter, true
+	}
+	ceiling := p.BaseDelay << (n - 1)
+	if ceiling <= 0 || (p.MaxDelay > 0 && ceiling > p.MaxDelay) {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0, true
+	}
+	return rand.N(ceiling) + 1, true
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// Breaker fails calls fast once Threshold consecutive calls have failed
+// with ErrUnavailable. After Cooldown it lets one call through: success
+// closes the circuit, failure opens it for another Cooldown. One breaker is
+// shared by all clients of a provider.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewBreaker returns a breaker, or nil (never open) when threshold is not
+// positive.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Open reports whether calls are currently failing fast.
+func (b *Breaker) Open() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold && (b.now().Before(b.openUntil) || b.probing)
+}
+
+func (b *Breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.now().Before(b.openUntil) || b.probing {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record counts the outcome of an allowed call. Calls abandoned by their
+// caller say nothing about the provider.
+func (b *Breaker) record(ctx context.Context, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	switch {
+	case errors.Is(err, ErrUnavailable):
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openUntil = b.now().Add(b.cooldown)
+		}
+	case ctx.Err() == nil:
+		b.failures = 0
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// VerifyPromptVersion identifies the blind answering prompt in stored
+// verification records.
+const VerifyPromptVersion = "verify-v1"
+
+// BlindAnswer is a model's answer to a question asked without its answer key.
+type BlindAnswer struct {
+	Answer string `json:"answer"`
+	// Model is the model that answered; it is not part of the model's output.
+	Model string `json:"-"`
+}
+
+const verifySystemPrompt = `You answer trivia questions. Give the single best factual answer as strict JSON: {"answer": "..."}. The answer is the shortest canonical name or value (1-3 words); for a year, date or quantity give the plain value (e.g. "1969", "1969-07-20", "8849 m"). Do not include any prose or Markdown.`
+
+// AnswerQuestion answers text at temperature 0, independently of the call
+// that generated it, so the service can check a question is answerable as
+// written.
+func (g *OpenAIGenerator) AnswerQuestion(ctx context.Context, text string) (BlindAnswer, error) {
+	body := map[string]any{
+		"model":       g.model,
+		"temperature": 0,
+		"messages": []map[string]any{
+			{"role": "system", "content": verifySystemPrompt},
+			{"role": "user", "content": text},
+		},
+	}
+	content, model, err := g.api.chat(ctx, body, "answer", answerSchema)
+	if err != nil {
+		return BlindAnswer{}, err
+	}
+	var a BlindAnswer
+	if err := decodeOutput(content, g.api.structured, &a); err != nil {
+		return BlindAnswer{}, err
+	}
+	a.Answer = strings.TrimSpace(a.Answer)
+	if a.Answer == "" {
+		return BlindAnswer{}, &ValidationError{Output: "answer", Field: "answer", Reason: "is required"}
+	}
+	a.Model = model
+	if a.Model == "" {
+		a.Model = g.model
+	}
+	return a, nil
+}
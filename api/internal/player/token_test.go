@@ -0,0 +1,23 @@
+package player
+
+import "testing"
+
+func TestSignVerify(t *testing.T) {
+	s := NewSigner("secret")
+	token := s.Sign("0b7c8f0e-1111-4222-8333-944445555666")
+	id, err := s.Verify(token)
+	if err != nil || id != "0b7c8f0e-1111-4222-8333-944445555666" {
+		t.Fatalf("verify: %q %v", id, err)
+	}
+	for _, bad := range []string{
+		"",
+		"no-dot",
+		"0b7c8f0e-1111-4222-8333-944445555666.",
+		"another-id" + token[len("0b7c8f0e-1111-4222-8333-944445555666"):],
+		NewSigner("other").Sign("0b7c8f0e-1111-4222-8333-944445555666"),
+	} {
+		if _, err := s.Verify(bad); err != ErrInvalidToken {
+			t.Errorf("Verify(%q) err = %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package scheduler runs a job on a cron schedule inside the API process,
+// guarded by a lock so only one replica runs each occurrence.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"time"
+)
+
+// Locker grants a cross-process lock. unlock must be called when ok is true.
+type Locker interface {
+	TryLock(ctx context.Context, key int64) (unlock func(), ok bool, err error)
+}
+
+// Job is the work run on each occurrence.
+type Job func(ctx context.Context) error
+
+// Scheduler runs Job at every occurrence of Schedule in Location.
+type Scheduler struct {
+	Schedule Schedule
+	Location *time.Location
+	// Jitter delays each run by a random duration in [0, Jitter).
+	Jitter time.Duration
+	// MaxRetries is how many times a failed run is retried when Retryable
+	// reports true. Backoff doubles from InitialBackoff up to MaxBackoff.
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Retryable      func(error) bool
+	Locker         Locker
+	LockKey        int64
+	Job            Job
+	Logger         *log.Logger
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Run blocks until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.defaults()
+	for {
+		next := s.Schedule.Next(s.now().In(s.Location))
+		if next.IsZero() {
+			s.Logger.Printf("[scheduler] schedule never fires; stopping")
+			return
+		}
+		wait := next.Sub(s.now())
+		if s.Jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(s.Jitter)))
+		}
+		s.Logger.Printf("[scheduler] next run at %s", s.now().Add(wait).In(s.Location).Format(time.RFC3339))
+		if err := s.sleep(ctx, wait); err != nil {
+			return
+		}
+		s.RunOnce(ctx)
+	}
+}
+
+// RunOnce takes the lock and runs the job, retrying with backoff.
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	s.defaults()
+	if s.Locker != nil {
+		unlock, ok, err := s.Locker.TryLock(ctx, s.LockKey)
+		if err != nil {
+			s.Logger.Printf("[scheduler] lock error: %v", err)
+			return
+		}
+		if !ok {
+			s.Logger.Printf("[scheduler] another instance holds the lock; skipping")
+			return
+		}
+		defer unlock()
+	}
+	backoff := s.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := s.Job(ctx)
+		if err == nil {
+			s.Logger.Printf("[scheduler] run succeeded")
+			return
+		}
+		if attempt >= s.MaxRetries || s.Retryable == nil || !s.Retryable(err) {
+			s.Logger.Printf("[scheduler] run failed: %v", err)
+			return
+		}
+		s.Logger.Printf("[scheduler] run failed (retry %d/%d in %s): %v", attempt+1, s.MaxRetries, backoff, err)
+		if err := s.sleep(ctx, backoff); err != nil {
+			return
+		}
+		backoff *= 2
+		if s.MaxBackoff > 0 && backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
+
+func (s *Scheduler) defaults() {
+	if s.Location == nil {
+		s.Location = time.UTC
+	}
+	if s.Logger == nil {
+		s.Logger = log.Default()
+	}
+	if s.InitialBackoff <= 0 {
+		s.InitialBackoff = time.Minute
+	}
+	if s.now == nil {
+		s.now = time.Now
+	}
+	if s.sleep == nil {
+		s.sleep = sleepCtx
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
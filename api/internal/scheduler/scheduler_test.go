@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	cases := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"0 6 * * *", time.Date(2026, 10, 16, 5, 59, 30, 0, time.UTC), time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC)},
+		{"0 6 * * *", time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC), time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC)},
+		{"*/15 9-17 * * 1-5", time.Date(2026, 10, 16, 17, 50, 0, 0, time.UTC), time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"30 0 1,15 * *", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), time.Date(2026, 11, 1, 0, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)},
+		{"5 0 * * *", time.Date(2026, 10, 16, 12, 0, 0, 0, ny), time.Date(2026, 10, 17, 0, 5, 0, 0, ny)},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", c.expr, err)
+		}
+		if got := s.Next(c.from); !got.Equal(c.want) {
+			t.Errorf("%q from %s: got %s want %s", c.expr, c.from, got, c.want)
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+type fakeLocker struct{ held, unlocked bool }
+
+func (l *fakeLocker) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	if l.held {
+		return nil, false, nil
+	}
+	return func() { l.unlocked = true }, true, nil
+}
+
+var errRetry = errors.New("retry me")
+
+func TestRunOnceRetriesWithBackoff(t *testing.T) {
+	var sleeps []time.Duration
+	calls := 0
+	lock := &fakeLocker{}
+	s := &Scheduler{
+		MaxRetries:     3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     3 * time.Second,
+		Retryable:      func(err error) bool { return errors.Is(err, errRetry) },
+		Locker:         lock,
+		Logger:         log.New(io.Discard, "", 0),
+		Job: func(ctx context.Context) error {
+			calls++
+			if calls < 4 {
+				return errRetry
+			}
+			return nil
+		},
+		sleep: func(ctx context.Context, d time.Duration) error {
+			sleeps = append(sleeps, d)
+			return nil
+		},
+	}
+	s.RunOnce(context.Background())
+	if calls != 4 {
+		t.Fatalf("calls = %d, want 4", calls)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	if len(sleeps) != len(want) {
+		t.Fatalf("sleeps = %v, want %v", sleeps, want)
+	}
+	for i := range want {
+		if sleeps[i] != want[i] {
+			t.Fatalf("sleeps = %v, want %v", sleeps, want)
+		}
+	}
+	if !lock.unlocked {
+		t.Fatalf("lock not released")
+	}
+}
+
+func TestRunOnceSkipsWhenLocked(t *testing.T) {
+	calls := 0
+	s := &Scheduler{
+		Locker: &fakeLocker{held: true},
+		Logger: log.New(io.Discard, "", 0),
+		Job:    func(ctx context.Context) error { calls++; return nil },
+	}
+	s.RunOnce(context.Background())
+	if calls != 0 {
+		t.Fatalf("job ran without the lock")
+	}
+}
+
+func TestRunOnceDoesNotRetryOtherErrors(t *testing.T) {
+	calls := 0
+	s := &Scheduler{
+		MaxRetries: 5,
+		Retryable:  func(err error) bool { return errors.Is(err, errRetry) },
+		Logger:     log.New(io.Discard, "", 0),
+		Job:        func(ctx context.Context) error { calls++; return errors.New("db down") },
+		sleep:      func(ctx context.Context, d time.Duration) error { return nil },
+	}
+	s.RunOnce(context.Background())
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"qotd/api/internal/db"
+)
+
+// MaxQuestionPage caps the page size of ListQuestions.
+const MaxQuestionPage = 200
+
+var (
+	ErrInvalidQuestion   = errors.New("invalid question")
+	ErrDuplicateQuestion = errors.New("question text matches another question")
+	ErrSimilarQuestion   = errors.New("question text is too similar to another question")
+	ErrEmbedFailed       = errors.New("could not embed question")
+)
+
+// QuestionPatch is an admin edit. Nil fields are left unchanged.
+type QuestionPatch struct {
+	Title   *string
+	Text    *string
+	Topic   *string
+	Choices []string
+}
+
+// ListQuestions returns a page of questions matching f and the number of
+// questions that match in total.
+func (s *QuestionService) ListQuestions(ctx context.Context, f db.QuestionFilter) ([]db.Question, int, error) {
+	if f.Limit <= 0 || f.Limit > MaxQuestionPage {
+		f.Limit = MaxQuestionPage
+	}
+	if f.From != nil {
+		from := CivilDate(*f.From)
+		f.From = &from
+	}
+	if f.To != nil {
+		to := CivilDate(*f.To)
+		f.To = &to
+	}
+	return s.repo.ListQuestions(ctx, f)
+}
+
+// Question returns a question by id, published or not.
+func (s *QuestionService) Question(ctx context.Context, id string) (db.Question, error) {
+	q, err := s.repo.GetQuestionByID(ctx, id)
+	if errors.Is(err, db.ErrNotFound) {
+		return db.Question{}, ErrQuestionNotFound
+	}
+	return q, err
+}
+
+// EditQuestion updates a question's title, text, topic or choices. The sha,
+// embedding and choice keys are derived and checked against the other
+// questions as for generated ones: a text already used is rejected with
+// ErrDuplicateQuestion, one too close to another with ErrSimilarQuestion,
+// and choices another question accepts with ErrDuplicateChoices. A
+// multiple-choice question's choices must still accept its correct option
+// and none of the others. Cached verdicts are dropped when the choices
+// change; stored answers keep their scores until regraded.
+func (s *QuestionService) EditQuestion(ctx context.Context, id string, p QuestionPatch) (db.Question, error) {
+	q, err := s.Question(ctx, id)
+	if err != nil {
+		return db.Question{}, err
+	}
+	edit := db.QuestionEdit{Title: q.Title, Text: q.Text, Topic: q.Topic}
+	if p.Title != nil {
+		edit.Title = strings.TrimSpace(*p.Title)
+		if edit.Title == "" {
+			return db.Question{}, fmt.Errorf("%w: title is required", ErrInvalidQuestion)
+		}
+	}
+	if p.Topic != nil {
+		edit.Topic = strings.TrimSpace(*p.Topic)
+	}
+	if p.Text != nil {
+		edit.Text = strings.TrimSpace(*p.Text)
+		if !validQuestionText(edit.Text) {
+			return db.Question{}, fmt.Errorf("%w: text must be %d to %d bytes", ErrInvalidQuestion, minQuestionText, maxQuestionText)
+		}
+	}
+	edit.SHA = questionSHA(edit.Text)
+	if edit.Text != q.Text {
+		if edit.Embedding, err = s.embedQuestion(ctx, edit.Text); err != nil {
+			s.logger.Printf("[admin] question %s: %v", id, err)
+			return db.Question{}, ErrEmbedFailed
+		}
+		sim, err := s.repo.MaxSimilarity(ctx, edit.Embedding, id)
+		if err != nil {
+			return db.Question{}, err
+		}
+		if sim >= maxSimilarity {
+			return db.Question{}, ErrSimilarQuestion
+		}
+	}
+	if p.Choices != nil {
+		var choices []string
+		for _, c := range p.Choices {
+			if c = strings.TrimSpace(c); c != "" {
+				choices = append(choices, c)
+			}
+		}
+		if len(choices) == 0 {
+			return db.Question{}, fmt.Errorf("%w: at least one choice is required", ErrInvalidQuestion)
+		}
+		if err := checkOptionChoices(q, choices); err != nil {
+			return db.Question{}, err
+		}
+		keys := choiceKeys(choices)
+		overlap, err := s.repo.HasChoiceOverlap(ctx, keys.Normalized, id)
+		if err != nil {
+			return db.Question{}, err
+		}
+		if overlap {
+			return db.Question{}, ErrDuplicateChoices
+		}
+		keys.Embeddings = s.embedChoices(ctx, choices)
+		edit.Choices = &keys
+	}
+	saved, err := s.repo.UpdateQuestion(ctx, id, edit)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrNotFound):
+			return db.Question{}, ErrQuestionNotFound
+		case errors.Is(err, db.ErrDuplicateSHA):
+			return db.Question{}, ErrDuplicateQuestion
+		case errors.Is(err, db.ErrDuplicateChoiceSig):
+			return db.Question{}, ErrDuplicateChoices
+		}
+		return db.Question{}, err
+	}
+	if edit.Choices != nil {
+		s.verdicts.dropQuestion(id)
+	}
+	return saved, nil
+}
+
+// checkOptionChoices makes sure new choices for a multiple-choice question
+// accept its correct option and none of the others, as generation does.
+func checkOptionChoices(q db.Question, choices []string) error {
+	for i, o := range q.Options {
+		switch accepted := matchesChoice(o, choices); {
+		case i == q.CorrectOption && !accepted:
+			return fmt.Errorf("%w: choices must accept the correct option %q", ErrInvalidQuestion, o)
+		case i != q.CorrectOption && accepted:
+			return fmt.Errorf("%w: choices must not accept option %q", ErrInvalidQuestion, o)
+		}
+	}
+	return nil
+}
+
+// DeleteQuestion removes a question together with its answers.
+func (s *QuestionService) DeleteQuestion(ctx context.Context, id string) error {
+	err := s.repo.DeleteQuestion(ctx, id)
+	if errors.Is(err, db.ErrNotFound) {
+		return ErrQuestionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.verdicts.dropQuestion(id)
+	return nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"qotd/api/internal/db"
+	"qotd/api/internal/llm"
+	txt "qotd/api/internal/text"
+)
+
+// Tolerance widens what numeric, year and date answers accept. The zero value
+// only accepts exact answers.
+type Tolerance struct {
+	// Numeric is relative: 0.01 accepts answers within 1% of the correct value.
+	Numeric float64
+	// Years accepted either side of the correct year.
+	Years int
+	// Days accepted either side of the correct date.
+	Days int
+}
+
+// answerType returns the answer type a generated question can be graded
+// with: the requested one when every choice parses as that type, otherwise
+// free text.
+func answerType(requested llm.AnswerType, choices []string) llm.AnswerType {
+	switch requested {
+	case llm.AnswerNumeric, llm.AnswerYear, llm.AnswerDate:
+	default:
+		return llm.AnswerFreeText
+	}
+	for _, c := range choices {
+		if !parsesAs(requested, c) {
+			return llm.AnswerFreeText
+		}
+	}
+	return requested
+}
+
+func parsesAs(t llm.AnswerType, s string) bool {
+	switch t {
+	case llm.AnswerNumeric:
+		_, ok := txt.ParseQuantity(s)
+		return ok
+	case llm.AnswerYear:
+		_, ok := txt.ParseYear(s)
+		return ok
+	case llm.AnswerDate:
+		_, ok := txt.ParseDate(s)
+		return ok
+	}
+	return false
+}
+
+// gradeTyped grades numeric, year and date answers locally. It reports false
+// for free-text questions, for questions whose choices do not parse and for
+// answers that do not parse, which are left to the later tiers.
+func (s *QuestionService) gradeTyped(q db.Question, answerText string) (grading, bool) {
+	t := llm.AnswerType(q.AnswerType)
+	if t == llm.AnswerFreeText || t == "" || len(q.Choices) == 0 || answerType(t, q.Choices) != t {
+		return grading{}, false
+	}
+	var accepted bool
+	switch t {
+	case llm.AnswerNumeric:
+		got, ok := parseAnswer(txt.ParseQuantity, answerText)
+		if !ok {
+			return grading{}, false
+		}
+		for _, c := range q.Choices {
+			want, _ := txt.ParseQuantity(c)
+			accepted = accepted || txt.SameQuantity(got, want, s.tolerance.Numeric)
+		}
+	case llm.AnswerYear:
+		got, ok := parseAnswer(txt.ParseYear, answerText)
+		if !ok {
+			return grading{}, false
+		}
+		for _, c := range q.Choices {
+			want, _ := txt.ParseYear(c)
+			accepted = accepted || abs(got-want) <= s.tolerance.Years
+		}
+	case llm.AnswerDate:
+		got, ok := parseAnswer(txt.ParseDate, answerText)
+		if !ok {
+			return grading{}, false
+		}
+		for _, c := range q.Choices {
+			want, _ := txt.ParseDate(c)
+			accepted = accepted || abs(int(got.Sub(want)/(24*time.Hour))) <= s.tolerance.Days
+		}
+	}
+	if accepted {
+		return grading{score: 10, feedback: "Accepted answer.", prov: db.Provenance{Tier: string(t)}}, true
+	}
+	return grading{score: 0, feedback: "Answer not recognized as acceptable.", prov: db.Provenance{Tier: string(t)}}, true
+}
+
+// parseAnswer parses an answer as given or, failing that, without the
+// sentence punctuation around it, so "1969." and "1969!" parse. A leading
+// apostrophe is kept: "'69" is not the year 69.
+func parseAnswer[T any](parse func(string) (T, bool), answer string) (T, bool) {
+	if v, ok := parse(answer); ok {
+		return v, true
+	}
+	return parse(strings.TrimRight(strings.Trim(strings.TrimSpace(answer), `"`), ".!?,;:"))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
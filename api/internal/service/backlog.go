@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"qotd/api/internal/db"
+)
+
+// MaxBacklogFill caps how many questions one FillBacklog call may generate.
+const MaxBacklogFill = 60
+
+// BacklogStatus describes the queue of generated, not yet published questions.
+type BacklogStatus struct {
+	Depth int
+	// Added lists questions queued by the FillBacklog call that produced this status.
+	Added []db.Question
+}
+
+// BacklogDepth reports how many queued questions are waiting for a day.
+func (s *QuestionService) BacklogDepth(ctx context.Context) (int, error) {
+	return s.repo.BacklogDepth(ctx)
+}
+
+// FillBacklog generates questions until target are queued. Each day GetToday
+// promotes the oldest queued question, so a filled backlog keeps the site
+// running through LLM outages. On ErrGenerateFailed the questions queued so
+// far are still returned.
+func (s *QuestionService) FillBacklog(ctx context.Context, target int) (BacklogStatus, error) {
+	return s.fillBacklog(ctx, target, nil, nil)
+}
+
+// fillBacklog is FillBacklog reporting each candidate to record and each
+// queued question to added; either may be nil.
+func (s *QuestionService) fillBacklog(ctx context.Context, target int, record func(db.JobAttempt), added func(db.Question)) (BacklogStatus, error) {
+	if target > MaxBacklogFill {
+		target = MaxBacklogFill
+	}
+	depth, err := s.repo.BacklogDepth(ctx)
+	if err != nil {
+		return BacklogStatus{}, err
+	}
+	status := BacklogStatus{Depth: depth}
+	for status.Depth < target {
+		c, err := s.generateCandidate(ctx, record)
+		if err != nil {
+			return status, err
+		}
+		c.question.Queued = true
+		c.question.Status = s.generatedStatus(db.StatusApproved)
+		saved, err := s.repo.InsertQuestion(ctx, c.question)
+		if err != nil {
+			return status, err
+		}
+		s.logger.Printf("[backlog] queued question id=%s sim=%.3f depth=%d", saved.ID, c.similarity, status.Depth+1)
+		status.Added = append(status.Added, saved)
+		if added != nil {
+			added(saved)
+		}
+		status.Depth++
+	}
+	return status, nil
+}
+
+// promote publishes the oldest queued question on day.
+func (s *QuestionService) promote(ctx context.Context, day time.Time) (db.Question, error) {
+	q, err := s.repo.PromoteNext(ctx, day)
+	switch {
+	case err == nil:
+		s.logger.Printf("[backlog] promoted question id=%s date=%s", q.ID, day.Format(time.DateOnly))
+		return q, nil
+	case errors.Is(err, db.ErrDateTaken):
+		// Another request promoted or published a question for day first.
+		return s.GetByDate(ctx, day)
+	case errors.Is(err, db.ErrNotFound):
+		return db.Question{}, ErrNoQuestion
+	default:
+		return db.Question{}, err
+	}
+}
+
+// PublishToday makes sure today has a question: it keeps the one already
+// published, promotes the oldest approved queued question, or generates a
+// new one. With review required the result may be a draft.
+func (s *QuestionService) PublishToday(ctx context.Context) (db.Question, error) {
+	q, err := s.GetToday(ctx)
+	if !errors.Is(err, ErrNoQuestion) {
+		return q, err
+	}
+	res, err := s.GenerateQuestion(ctx, GenerateOptions{})
+	if errors.Is(err, ErrAlreadyPublished) {
+		// Today's date may be held by a draft awaiting review.
+		return s.repo.GetQuestionByDate(ctx, s.Today())
+	}
+	return res.Question, err
+}
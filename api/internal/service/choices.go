@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+
+	"qotd/api/internal/llm"
+)
+
+// distractorCount is how many wrong options a multiple-choice question has.
+const distractorCount = 3
+
+// buildOptions checks a generated multiple-choice question and returns its
+// shuffled options, the index of the correct one and the aliases with the
+// correct answer included.
+func buildOptions(q llm.Question) (options []string, correct int, aliases []string, err error) {
+	answer := strings.TrimSpace(q.Correct)
+	if normalizeAnswer(answer) == "" {
+		return nil, 0, nil, errors.New("missing correct answer")
+	}
+	aliases = q.Choices
+	if !matchesChoice(answer, aliases) {
+		aliases = append([]string{answer}, aliases...)
+	}
+	if len(q.Distractors) != distractorCount {
+		return nil, 0, nil, errors.New("need exactly 3 distractors")
+	}
+	seen := map[string]bool{}
+	options = []string{answer}
+	for _, d := range q.Distractors {
+		d = strings.TrimSpace(d)
+		n := normalizeAnswer(d)
+		if n == "" {
+			return nil, 0, nil, errors.New("empty distractor")
+		}
+		if seen[n] {
+			return nil, 0, nil, errors.New("duplicate distractor")
+		}
+		if matchesChoice(d, aliases) {
+			return nil, 0, nil, errors.New("distractor matches an accepted answer")
+		}
+		seen[n] = true
+		options = append(options, d)
+	}
+	rand.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	for i, o := range options {
+		if o == answer {
+			correct = i
+			break
+		}
+	}
+	return options, correct, aliases, nil
+}
+
+// pickOption resolves an answer to a multiple-choice option, by the
+// option's text or else by label ("b", "(2)"). Text comes first so that "2"
+// picks the option "2" rather than the second option.
+func pickOption(input string, options []string) (int, bool) {
+	if norm := normalizeAnswer(input); norm != "" {
+		for i, o := range options {
+			if norm == normalizeAnswer(o) {
+				return i, true
+			}
+		}
+	}
+	label := strings.Trim(strings.TrimSpace(strings.ToLower(input)), "(). ")
+	if len(label) != 1 {
+		return 0, false
+	}
+	r := rune(label[0])
+	idx := -1
+	switch {
+	case r >= 'a' && r <= 'z':
+		idx = int(r - 'a')
+	case r >= '1' && r <= '9':
+		idx = int(r - '1')
+	}
+	if idx < 0 || idx >= len(options) {
+		return 0, false
+	}
+	return idx, true
+}
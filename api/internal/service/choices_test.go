@@ -0,0 +1,30 @@
+package service
+
+import "testing"
+
+func TestPickOption(t *testing.T) {
+	words := []string{"Paris", "Lyon", "Marseille", "Nice"}
+	numerals := []string{"2", "1", "0", "3"}
+	cases := []struct {
+		input   string
+		options []string
+		want    int
+		ok      bool
+	}{
+		{"b", words, 1, true},
+		{"(3)", words, 2, true},
+		{"the nice", words, 3, true},
+		{"e", words, 0, false},
+		{"Toulouse", words, 0, false},
+		{"2", numerals, 0, true},
+		{"1", numerals, 1, true},
+		{"0", numerals, 2, true},
+		{"d", numerals, 3, true},
+	}
+	for _, c := range cases {
+		got, ok := pickOption(c.input, c.options)
+		if got != c.want || ok != c.ok {
+			t.Errorf("pickOption(%q, %q) = %d, %v; want %d, %v", c.input, c.options, got, ok, c.want, c.ok)
+		}
+	}
+}
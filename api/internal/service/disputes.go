@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"qotd/api/internal/db"
+	txt "qotd/api/internal/text"
+)
+
+var (
+	ErrAnswerNotFound   = errors.New("answer not found")
+	ErrAnswerAccepted   = errors.New("answer already accepted")
+	ErrAlreadyDisputed  = errors.New("answer already disputed")
+	ErrDisputeNotFound  = errors.New("dispute not found")
+	ErrDisputeClosed    = errors.New("dispute already resolved")
+	ErrDuplicateChoices = errors.New("choices match another question")
+)
+
+const (
+	tierDispute = "dispute"
+	tierRescore = "rescore"
+)
+
+// DisputeDetail is a dispute with the question it concerns.
+type DisputeDetail struct {
+	db.Dispute
+	Question db.Question
+}
+
+// Resolution is an admin's decision on a dispute.
+type Resolution struct {
+	// Accept scores the disputed answer as correct.
+	Accept bool
+	// AddAlias also adds Alias, or the disputed answer when Alias is empty,
+	// to the question's accepted choices and rescores past answers.
+	AddAlias bool
+	Alias    string
+	Note     string
+}
+
+type ResolveResult struct {
+	Dispute DisputeDetail
+	// Rescored counts other answers accepted because of a new alias.
+	Rescored int
+}
+
+// DisputeAnswer lets a player appeal the grade of one of their own answers.
+func (s *QuestionService) DisputeAnswer(ctx context.Context, answerID, userID, reason string) (db.Dispute, error) {
+	a, err := s.repo.GetAnswer(ctx, answerID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return db.Dispute{}, ErrAnswerNotFound
+		}
+		return db.Dispute{}, err
+	}
+	if a.UserID == "" || a.UserID != userID {
+		return db.Dispute{}, ErrAnswerNotFound
+	}
+	if a.Status == db.AnswerPending {
+		return db.Dispute{}, ErrAnswerPending
+	}
+	if a.Score >= 10 {
+		return db.Dispute{}, ErrAnswerAccepted
+	}
+	d, err := s.repo.InsertDispute(ctx, answerID, strings.TrimSpace(reason))
+	if errors.Is(err, db.ErrAlreadyDisputed) {
+		return db.Dispute{}, ErrAlreadyDisputed
+	}
+	return d, err
+}
+
+// Disputes lists disputes with the given status; empty means all.
+func (s *QuestionService) Disputes(ctx context.Context, status string) ([]DisputeDetail, error) {
+	disputes, err := s.repo.ListDisputes(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	questions := map[string]db.Question{}
+	out := make([]DisputeDetail, 0, len(disputes))
+	for _, d := range disputes {
+		q, ok := questions[d.Answer.QuestionID]
+		if !ok {
+			if q, err = s.repo.GetQuestionByID(ctx, d.Answer.QuestionID); err != nil {
+				return nil, err
+			}
+			questions[q.ID] = q
+		}
+		out = append(out, DisputeDetail{Dispute: d, Question: q})
+	}
+	return out, nil
+}
+
+// ResolveDispute closes an open dispute. Accepting it scores the disputed
+// answer 10 and can add a new alias to the question. The dispute is closed
+// last, so a failed alias or regrade leaves it open to retry.
+func (s *QuestionService) ResolveDispute(ctx context.Context, id string, res Resolution) (ResolveResult, error) {
+	d, err := s.repo.GetDispute(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return ResolveResult{}, ErrDisputeNotFound
+		}
+		return ResolveResult{}, err
+	}
+	if d.Status != db.DisputeOpen {
+		return ResolveResult{}, ErrDisputeClosed
+	}
+	status := db.DisputeRejected
+	var rescored int
+	if res.Accept {
+		status = db.DisputeAccepted
+		q, err := s.repo.GetQuestionByID(ctx, d.Answer.QuestionID)
+		if err != nil {
+			return ResolveResult{}, err
+		}
+		if res.AddAlias {
+			alias := strings.TrimSpace(res.Alias)
+			if alias == "" {
+				alias = d.Answer.Text
+			}
+			if q, err = s.addChoice(ctx, q, alias); err != nil {
+				return ResolveResult{}, err
+			}
+		}
+		prov := db.Provenance{Tier: tierDispute}
+		if err := s.repo.UpdateAnswerGrade(ctx, d.AnswerID, 10, "Accepted on appeal.", prov); err != nil {
+			return ResolveResult{}, err
+		}
+		if res.AddAlias {
+			if rescored, err = s.rescoreMatching(ctx, q); err != nil {
+				return ResolveResult{}, err
+			}
+		}
+	}
+	d, err = s.repo.ResolveDispute(ctx, id, status, strings.TrimSpace(res.Note))
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrNotFound):
+			return ResolveResult{}, ErrDisputeNotFound
+		case errors.Is(err, db.ErrDisputeClosed):
+			return ResolveResult{}, ErrDisputeClosed
+		}
+		return ResolveResult{}, err
+	}
+	q, err := s.repo.GetQuestionByID(ctx, d.Answer.QuestionID)
+	if err != nil {
+		return ResolveResult{}, err
+	}
+	return ResolveResult{Dispute: DisputeDetail{Dispute: d, Question: q}, Rescored: rescored}, nil
+}
+
+// AddAlias adds an accepted choice to a question and accepts every earlier
+// answer that now matches one of its choices. It returns how many answers
+// were rescored.
+func (s *QuestionService) AddAlias(ctx context.Context, questionID, alias string) (int, error) {
+	q, err := s.repo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return 0, ErrQuestionNotFound
+		}
+		return 0, err
+	}
+	if q, err = s.addChoice(ctx, q, alias); err != nil {
+		return 0, err
+	}
+	return s.rescoreMatching(ctx, q)
+}
+
+// addChoice stores alias as one of q's accepted choices unless one already
+// matches it, and returns the updated question.
+func (s *QuestionService) addChoice(ctx context.Context, q db.Question, alias string) (db.Question, error) {
+	if matchesChoice(alias, q.Choices) {
+		return q, nil
+	}
+	choices := append(append([]string(nil), q.Choices...), alias)
+	if err := s.updateChoices(ctx, q.ID, choices); err != nil {
+		return q, err
+	}
+	q.Choices = choices
+	return q, nil
+}
+
+// updateChoices stores new accepted choices with their normalized forms,
+// signature and embeddings, and forgets cached verdicts for the question.
+func (s *QuestionService) updateChoices(ctx context.Context, questionID string, choices []string) error {
+	u := choiceKeys(choices)
+	u.Embeddings = s.embedChoices(ctx, choices)
+	err := s.repo.UpdateQuestionChoices(ctx, questionID, u)
+	if errors.Is(err, db.ErrDuplicate) {
+		return ErrDuplicateChoices
+	}
+	if err != nil {
+		return err
+	}
+	s.verdicts.dropQuestion(questionID)
+	return nil
+}
+
+// rescoreMatching accepts the question's unaccepted answers that one of its
+// choices now matches locally.
+func (s *QuestionService) rescoreMatching(ctx context.Context, q db.Question) (int, error) {
+	answers, err := s.repo.ListAnswers(ctx, q.ID)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, a := range answers {
+		if a.Score >= 10 {
+			continue
+		}
+		m, ok := txt.MatchAnswer(a.Text, q.Choices)
+		if !ok {
+			continue
+		}
+		prov := db.Provenance{Tier: tierRescore, MatchedChoice: m.Choice}
+		if err := s.repo.UpdateAnswerGrade(ctx, a.ID, 10, "Accepted after the answer key was updated.", prov); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"qotd/api/internal/db"
+)
+
+// takenChoices is a store where every choice update collides with another
+// question.
+type takenChoices struct{ *db.MemoryStore }
+
+func (takenChoices) UpdateQuestionChoices(context.Context, string, db.ChoiceUpdate) error {
+	return db.ErrDuplicateChoiceSig
+}
+
+type noEmbedder struct{}
+
+func (noEmbedder) Embed(context.Context, string) ([]float32, error) {
+	return nil, errors.New("no embeddings")
+}
+
+func TestResolveDisputeKeepsItOpenWhenAliasFails(t *testing.T) {
+	ctx := context.Background()
+	store := takenChoices{db.NewMemoryStore()}
+	s := NewQuestionService(store, nil, noEmbedder{}, nil, log.New(io.Discard, "", 0), Options{})
+	q, err := store.InsertQuestion(ctx, db.NewQuestion{Title: "Capital", Text: "What is the capital of France?", SHA: "capital", Choices: []string{"Paris"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := store.InsertAnswer(ctx, db.NewAnswer{QuestionID: q.ID, UserID: "alice", Text: "Lutetia"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := store.InsertDispute(ctx, a.ID, "Lutetia is Paris.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ResolveDispute(ctx, d.ID, Resolution{Accept: true, AddAlias: true}); !errors.Is(err, ErrDuplicateChoices) {
+		t.Fatalf("resolve with alias: %v", err)
+	}
+	if d, err := store.GetDispute(ctx, d.ID); err != nil || d.Status != db.DisputeOpen || d.Answer.Score != 0 {
+		t.Fatalf("dispute after failed alias: %+v, %v", d, err)
+	}
+
+	res, err := s.ResolveDispute(ctx, d.ID, Resolution{Accept: true})
+	if err != nil || res.Dispute.Status != db.DisputeAccepted || res.Dispute.Answer.Score != 10 {
+		t.Fatalf("retry: %+v, %v", res, err)
+	}
+}
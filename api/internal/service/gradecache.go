@@ -0,0 +1,122 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+
+	"qotd/api/internal/db"
+)
+
+const tierCache = "cache"
+
+// cachedVerdict looks an answer up in the in-process LRU, then in the store.
+func (s *QuestionService) cachedVerdict(ctx context.Context, questionID, norm string) (grading, bool) {
+	if norm == "" {
+		return grading{}, false
+	}
+	key := verdictKey{questionID, norm}
+	v, ok := s.verdicts.get(key)
+	if !ok {
+		var err error
+		v, err = s.repo.GetGradeVerdict(ctx, questionID, norm)
+		if err != nil {
+			if !errors.Is(err, db.ErrNotFound) {
+				s.logger.Printf("[grade] cache lookup: %v", err)
+			}
+			return grading{}, false
+		}
+		s.verdicts.put(key, v)
+	}
+	return grading{score: v.Score, feedback: v.Feedback, prov: db.Provenance{Tier: tierCache, CachedTier: v.Tier}}, true
+}
+
+// storeVerdict caches g and returns the verdict that was kept, which differs
+// from g only when another request cached the same answer first.
+func (s *QuestionService) storeVerdict(ctx context.Context, questionID, norm string, g grading) grading {
+	if norm == "" {
+		return g
+	}
+	v, err := s.repo.PutGradeVerdict(ctx, questionID, norm, db.GradeVerdict{Score: g.score, Feedback: g.feedback, Tier: g.prov.Tier})
+	if err != nil {
+		s.logger.Printf("[grade] cache store: %v", err)
+		return g
+	}
+	s.verdicts.put(verdictKey{questionID, norm}, v)
+	if v.Score != g.score {
+		return grading{score: v.Score, feedback: v.Feedback, prov: db.Provenance{Tier: tierCache, CachedTier: v.Tier}}
+	}
+	return g
+}
+
+type verdictKey struct{ questionID, norm string }
+
+type verdictEntry struct {
+	key     verdictKey
+	verdict db.GradeVerdict
+}
+
+// verdictLRU is a fixed-size, least recently used cache of grade verdicts. A
+// nil *verdictLRU caches nothing.
+type verdictLRU struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[verdictKey]*list.Element
+}
+
+func newVerdictLRU(size int) *verdictLRU {
+	if size <= 0 {
+		return nil
+	}
+	return &verdictLRU{size: size, order: list.New(), items: map[verdictKey]*list.Element{}}
+}
+
+func (c *verdictLRU) get(key verdictKey) (db.GradeVerdict, bool) {
+	if c == nil {
+		return db.GradeVerdict{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return db.GradeVerdict{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*verdictEntry).verdict, true
+}
+
+func (c *verdictLRU) put(key verdictKey, v db.GradeVerdict) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*verdictEntry).verdict = v
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&verdictEntry{key: key, verdict: v})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*verdictEntry).key)
+	}
+}
+
+// dropQuestion forgets every verdict for a question.
+func (c *verdictLRU) dropQuestion(questionID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.items {
+		if key.questionID == questionID {
+			c.order.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"qotd/api/internal/db"
+)
+
+func TestVerdictLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newVerdictLRU(2)
+	a, b, d := verdictKey{"q", "a"}, verdictKey{"q", "b"}, verdictKey{"q", "d"}
+	c.put(a, db.GradeVerdict{Score: 10})
+	c.put(b, db.GradeVerdict{Score: 0})
+	if _, ok := c.get(a); !ok {
+		t.Fatal("a missing")
+	}
+	c.put(d, db.GradeVerdict{Score: 0})
+	if _, ok := c.get(b); ok {
+		t.Fatal("b should have been evicted")
+	}
+	if v, ok := c.get(a); !ok || v.Score != 10 {
+		t.Fatalf("a = %+v, %v", v, ok)
+	}
+	if _, ok := c.get(d); !ok {
+		t.Fatal("d missing")
+	}
+}
+
+func TestVerdictLRUDisabled(t *testing.T) {
+	c := newVerdictLRU(0)
+	c.put(verdictKey{"q", "a"}, db.GradeVerdict{Score: 10})
+	if _, ok := c.get(verdictKey{"q", "a"}); ok {
+		t.Fatal("disabled cache returned a verdict")
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"qotd/api/internal/db"
+)
+
+// ErrJobNotFound reports an unknown generation job.
+var ErrJobNotFound = errors.New("job not found")
+
+// jobLockKey is the advisory lock id held while running generation jobs, so
+// one replica runs them at a time.
+const jobLockKey int64 = 0x716f746a // "qotj"
+
+// StartGenerate queues a job generating the question described by opts and
+// starts running queued jobs in the background. A day that already has a
+// question fails with ErrAlreadyPublished before any job is queued.
+func (s *QuestionService) StartGenerate(ctx context.Context, opts GenerateOptions) (db.Job, error) {
+	day, err := s.generateDay(ctx, opts)
+	if err != nil {
+		return db.Job{}, err
+	}
+	job, err := s.repo.InsertJob(ctx, day, opts.Force)
+	if err != nil {
+		return db.Job{}, err
+	}
+	go s.runQueuedJobs(context.WithoutCancel(ctx))
+	return job, nil
+}
+
+// StartBacklogFill queues a job running FillBacklog up to target and starts
+// running queued jobs in the background.
+func (s *QuestionService) StartBacklogFill(ctx context.Context, target int) (db.Job, error) {
+	job, err := s.repo.InsertBacklogJob(ctx, target)
+	if err != nil {
+		return db.Job{}, err
+	}
+	go s.runQueuedJobs(context.WithoutCancel(ctx))
+	return job, nil
+}
+
+// RunJobs runs queued generation jobs every interval until ctx ends,
+// starting with any left over from before a restart.
+func (s *QuestionService) RunJobs(ctx context.Context, interval time.Duration) {
+	s.runQueuedJobs(ctx)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.runQueuedJobs(ctx)
+		}
+	}
+}
+
+// Job returns a generation job with the attempts made so far.
+func (s *QuestionService) Job(ctx context.Context, id string) (db.Job, error) {
+	j, err := s.repo.GetJob(ctx, id)
+	if errors.Is(err, db.ErrNotFound) {
+		return db.Job{}, ErrJobNotFound
+	}
+	return j, err
+}
+
+// runQueuedJobs claims and runs queued jobs one at a time until none are
+// left. It returns straight away while another caller, here or on another
+// replica, holds the job lock.
+func (s *QuestionService) runQueuedJobs(ctx context.Context) {
+	for {
+		unlock, ok, err := s.repo.TryLock(ctx, jobLockKey)
+		if err != nil {
+			s.logger.Printf("[job] lock error: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+		err = s.runClaimedJobs(ctx)
+		unlock()
+		if err != nil {
+			s.logger.Printf("[job] claim error: %v", err)
+			return
+		}
+		// A job queued just before unlock found the lock taken; pick it up.
+		if queued, err := s.repo.HasQueuedJobs(ctx); err != nil || !queued {
+			return
+		}
+	}
+}
+
+// runClaimedJobs runs jobs while holding the job lock.
+func (s *QuestionService) runClaimedJobs(ctx context.Context) error {
+	// Jobs only run under the lock, so one still running was cut off by a
+	// restart.
+	n, err := s.repo.FailRunningJobs(ctx, "interrupted by a restart")
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		s.logger.Printf("[job] failed %d interrupted jobs", n)
+	}
+	for {
+		job, err := s.repo.ClaimJob(ctx)
+		if errors.Is(err, db.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.runJob(ctx, job)
+	}
+}
+
+func (s *QuestionService) runJob(ctx context.Context, job db.Job) {
+	// Attempts are numbered across the job, which for a backlog fill spans
+	// several questions.
+	attempts := 0
+	record := func(a db.JobAttempt) {
+		attempts++
+		a.Number = attempts
+		if err := s.repo.AppendJobAttempt(ctx, job.ID, a); err != nil {
+			s.logger.Printf("[job] %s attempt %d not recorded: %v", job.ID, a.Number, err)
+		}
+	}
+	var questionID string
+	var err error
+	if job.Kind == db.JobBacklog {
+		_, err = s.fillBacklog(ctx, job.Target, record, func(q db.Question) {
+			if err := s.repo.AddJobQuestion(ctx, job.ID, q.ID); err != nil {
+				s.logger.Printf("[job] %s question %s not recorded: %v", job.ID, q.ID, err)
+			}
+		})
+	} else {
+		questionID, err = s.runGenerateJob(ctx, job, record)
+	}
+	status, msg := db.JobSucceeded, ""
+	if err != nil {
+		status, msg = db.JobFailed, err.Error()
+		s.logger.Printf("[job] %s failed: %v", job.ID, err)
+	}
+	if err := s.repo.FinishJob(ctx, job.ID, status, questionID, msg); err != nil {
+		s.logger.Printf("[job] %s finish error: %v", job.ID, err)
+	}
+}
+
+// runGenerateJob generates the question for a JobGenerate and returns its id.
+func (s *QuestionService) runGenerateJob(ctx context.Context, job db.Job, record func(db.JobAttempt)) (string, error) {
+	// An earlier job may have filled the day while this one was queued.
+	day, err := s.generateDay(ctx, GenerateOptions{Date: job.PublishDate, Force: job.Force})
+	if err != nil {
+		return "", err
+	}
+	res, err := s.generateFor(ctx, day, job.Force, record)
+	return res.Question.ID, err
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"qotd/api/internal/db"
+)
+
+func TestRunQueuedJobsFailsInterruptedJobs(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryStore()
+	s := NewQuestionService(store, nil, nil, nil, log.New(io.Discard, "", 0), Options{})
+	if _, err := store.InsertJob(ctx, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), false); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := store.ClaimJob(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another replica holds the lock, so its running job is not stale.
+	unlock, ok, err := store.TryLock(ctx, jobLockKey)
+	if err != nil || !ok {
+		t.Fatalf("lock: %v %v", ok, err)
+	}
+	s.runQueuedJobs(ctx)
+	if j, _ := store.GetJob(ctx, stale.ID); j.Status != db.JobRunning {
+		t.Fatalf("job run elsewhere: %+v", j)
+	}
+
+	unlock()
+	s.runQueuedJobs(ctx)
+	if j, _ := store.GetJob(ctx, stale.ID); j.Status != db.JobFailed || j.Error == "" || j.FinishedAt == nil {
+		t.Fatalf("interrupted job: %+v", j)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"qotd/api/internal/db"
+)
+
+var ErrInvalidPeriod = errors.New("invalid leaderboard period")
+
+// Period is a leaderboard window ending today.
+type Period string
+
+const (
+	PeriodDay  Period = "day"
+	PeriodWeek Period = "week"
+	PeriodAll  Period = "all"
+)
+
+// Leaderboard is a ranked window of publish dates.
+type Leaderboard struct {
+	Period Period
+	From   time.Time
+	To     time.Time
+	Rows   []db.LeaderboardRow
+}
+
+// Leaderboard ranks players over today, the current week (from Monday) or
+// all time. Rankings trail new answers until the next RefreshLeaderboard.
+func (s *QuestionService) Leaderboard(ctx context.Context, period Period, limit int) (Leaderboard, error) {
+	to := s.Today()
+	var from time.Time
+	switch period {
+	case PeriodDay:
+		from = to
+	case PeriodWeek:
+		offset := (int(to.Weekday()) + 6) % 7
+		from = to.AddDate(0, 0, -offset)
+	case PeriodAll:
+		from = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return Leaderboard{}, ErrInvalidPeriod
+	}
+	rows, err := s.repo.Leaderboard(ctx, db.LeaderboardQuery{From: from, To: to, Location: s.loc, Limit: limit})
+	if err != nil {
+		return Leaderboard{}, err
+	}
+	return Leaderboard{Period: period, From: from, To: to, Rows: rows}, nil
+}
+
+// RefreshLeaderboard rebuilds the aggregates behind Leaderboard.
+func (s *QuestionService) RefreshLeaderboard(ctx context.Context) error {
+	return s.repo.RefreshLeaderboard(ctx)
+}
+
+// RunLeaderboardRefresh refreshes the leaderboard every interval until ctx ends.
+func (s *QuestionService) RunLeaderboardRefresh(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := s.RefreshLeaderboard(ctx); err != nil {
+				s.logger.Printf("[leaderboard] refresh error: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"qotd/api/internal/db"
+)
+
+// ErrAnswerPending is returned for actions that need a graded answer.
+var ErrAnswerPending = errors.New("answer is still being graded")
+
+const (
+	// pendingBatch caps how many pending answers one pass grades.
+	pendingBatch = 50
+	// pendingLease keeps answers claimed by a pass from other replicas'
+	// passes while it grades them.
+	pendingLease = 10 * time.Minute
+	// maxGradeRetry caps the backoff between tries for one answer.
+	maxGradeRetry = time.Hour
+	// maxGradeAttempts is how many tries a pending answer gets, about four
+	// hours with the default interval, before it is given up on.
+	maxGradeAttempts = 10
+	pendingFeedback  = "Grading is delayed; check back shortly."
+	ungradedFeedback = "This answer could not be graded. Dispute it to have it reviewed."
+	// tierUngraded marks an answer scored 0 because grading was given up.
+	tierUngraded = "ungraded"
+)
+
+// Answer returns an answer for its player, or for anyone when it was given
+// anonymously.
+func (s *QuestionService) Answer(ctx context.Context, id, userID string) (db.Answer, error) {
+	a, err := s.repo.GetAnswer(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return db.Answer{}, ErrAnswerNotFound
+		}
+		return db.Answer{}, err
+	}
+	if a.UserID != "" && a.UserID != userID {
+		return db.Answer{}, ErrAnswerNotFound
+	}
+	return a, nil
+}
+
+// GradePending claims the pending answers that are due, grades them and
+// returns how many it graded. An answer the grader is unavailable for waits
+// twice as long as last time, and once it is unavailable the rest of the
+// batch is deferred the same way without calling it. Any other grading
+// error, or running out of attempts, scores the answer 0 so that it stops
+// being pending; its player can still dispute it.
+func (s *QuestionService) GradePending(ctx context.Context) (int, error) {
+	now := s.now()
+	due, err := s.repo.ClaimDueAnswers(ctx, now, now.Add(pendingLease), pendingBatch)
+	if err != nil {
+		return 0, err
+	}
+	graded := 0
+	questions := map[string]db.Question{}
+	var unavailable error
+	for _, a := range due {
+		var g grading
+		err := unavailable
+		if err == nil {
+			q, ok := questions[a.QuestionID]
+			if !ok {
+				if q, err = s.repo.GetQuestionByID(ctx, a.QuestionID); err != nil {
+					// Release the answer rather than leaving it claimed.
+					if derr := s.deferGrade(ctx, a, err); derr != nil {
+						return graded, derr
+					}
+					continue
+				}
+				questions[a.QuestionID] = q
+			}
+			g, err = s.grade(ctx, q, a.Text)
+		}
+		if errors.Is(err, ErrGradingUnavailable) {
+			unavailable = err
+		}
+		switch {
+		case errors.Is(err, ErrGradingUnavailable) && a.GradeAttempts+1 < maxGradeAttempts:
+			if derr := s.deferGrade(ctx, a, err); derr != nil {
+				return graded, derr
+			}
+			continue
+		case err != nil:
+			s.logger.Printf("[pending] answer %s: %v; giving up after %d tries", a.ID, err, a.GradeAttempts+1)
+			g = grading{feedback: ungradedFeedback, prov: db.Provenance{Tier: tierUngraded}}
+		}
+		err = s.repo.GradePendingAnswer(ctx, a.ID, g.score, g.feedback, g.prov)
+		if errors.Is(err, db.ErrNotFound) {
+			// Graded meanwhile, e.g. by a rescore or an admin.
+			continue
+		}
+		if err != nil {
+			return graded, err
+		}
+		graded++
+	}
+	return graded, nil
+}
+
+// deferGrade releases a claimed answer that could not be graded until its
+// next try.
+func (s *QuestionService) deferGrade(ctx context.Context, a db.Answer, cause error) error {
+	delay := s.retryDelay(a.GradeAttempts)
+	if err := s.repo.DeferAnswerGrade(ctx, a.ID, s.now().Add(delay)); err != nil && !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+	s.logger.Printf("[pending] answer %s: %v; retrying in %s", a.ID, cause, delay)
+	return nil
+}
+
+// retryDelay is the wait after the given number of failed tries plus one.
+func (s *QuestionService) retryDelay(attempts int) time.Duration {
+	d := s.gradeRetry
+	for i := 0; i < attempts && d < maxGradeRetry; i++ {
+		d *= 2
+	}
+	return min(d, maxGradeRetry)
+}
+
+// RunPendingGrader grades pending answers every interval until ctx ends.
+func (s *QuestionService) RunPendingGrader(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			n, err := s.GradePending(ctx)
+			if err != nil {
+				s.logger.Printf("[pending] grade error: %v", err)
+			}
+			if n > 0 {
+				s.logger.Printf("[pending] graded %d answers", n)
+			}
+		}
+	}
+}
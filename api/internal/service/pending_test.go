@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"qotd/api/internal/db"
+	"qotd/api/internal/llm"
+)
+
+func TestGradePendingSkipsClaimedAnswers(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := db.NewMemoryStore().WithClock(clock)
+	// No grader: calling it would panic.
+	s := NewQuestionService(store, nil, nil, nil, log.New(io.Discard, "", 0), Options{Now: clock, GradeRetry: time.Minute})
+	q, err := store.InsertQuestion(ctx, db.NewQuestion{Title: "Capital", Text: "What is the capital of France?", SHA: "capital", Choices: []string{"Paris"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := store.InsertAnswer(ctx, db.NewAnswer{QuestionID: q.ID, Text: "Lutetia", Status: db.AnswerPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another replica's pass holds the answer.
+	claimed, err := store.ClaimDueAnswers(ctx, now, now.Add(pendingLease), pendingBatch)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("claim: %v %v", claimed, err)
+	}
+	if n, err := s.GradePending(ctx); n != 0 || err != nil {
+		t.Fatalf("pass over a claimed answer: %d %v", n, err)
+	}
+
+	if err := store.GradePendingAnswer(ctx, a.ID, 10, "Correct.", db.Provenance{Tier: "llm"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.GradePendingAnswer(ctx, a.ID, 0, "Wrong.", db.Provenance{Tier: "llm"}); !errors.Is(err, db.ErrNotFound) {
+		t.Fatalf("second grade: %v", err)
+	}
+	if got, _ := store.GetAnswer(ctx, a.ID); got.Score != 10 || got.Status != db.AnswerGraded {
+		t.Fatalf("answer: %+v", got)
+	}
+}
+
+type failingGrader struct{ err error }
+
+func (g failingGrader) Grade(context.Context, string, []string) (llm.GradeResult, error) {
+	return llm.GradeResult{}, g.err
+}
+
+func TestGradePendingGivesUp(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := db.NewMemoryStore().WithClock(clock)
+	q, err := store.InsertQuestion(ctx, db.NewQuestion{Title: "Capital", Text: "What is the capital of France?", SHA: "capital", Choices: []string{"Paris"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := func(tries int) string {
+		t.Helper()
+		a, err := store.InsertAnswer(ctx, db.NewAnswer{QuestionID: q.ID, Text: "Lutetia", Status: db.AnswerPending})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < tries; i++ {
+			if err := store.DeferAnswerGrade(ctx, a.ID, now); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return a.ID
+	}
+	pass := func(grader llm.Grader) {
+		t.Helper()
+		s := NewQuestionService(store, grader, nil, nil, log.New(io.Discard, "", 0), Options{Now: clock, GradeRetry: time.Minute})
+		if _, err := s.GradePending(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(name, id, status, tier string) {
+		t.Helper()
+		a, err := store.GetAnswer(ctx, id)
+		if err != nil || a.Status != status || a.Provenance.Tier != tier || a.Score != 0 {
+			t.Fatalf("%s: %+v, %v", name, a, err)
+		}
+	}
+
+	rejected := pending(0)
+	pass(failingGrader{&llm.StatusError{StatusCode: 401}})
+	check("rejected request", rejected, db.AnswerGraded, tierUngraded)
+
+	waiting, lastTry := pending(maxGradeAttempts-2), pending(maxGradeAttempts-1)
+	pass(failingGrader{llm.ErrUnavailable})
+	check("unavailable", waiting, db.AnswerPending, "")
+	check("out of tries", lastTry, db.AnswerGraded, tierUngraded)
+	if a, _ := store.GetAnswer(ctx, lastTry); a.Feedback != ungradedFeedback {
+		t.Fatalf("feedback = %q", a.Feedback)
+	}
+}
+
+// brokenQuestion is a store that fails to load one question.
+type brokenQuestion struct {
+	*db.MemoryStore
+	id string
+}
+
+func (s brokenQuestion) GetQuestionByID(ctx context.Context, id string) (db.Question, error) {
+	if id == s.id {
+		return db.Question{}, errors.New("connection reset")
+	}
+	return s.MemoryStore.GetQuestionByID(ctx, id)
+}
+
+type verdictGrader struct{ match bool }
+
+func (g verdictGrader) Grade(_ context.Context, _ string, choices []string) (llm.GradeResult, error) {
+	return llm.GradeResult{Match: g.match, Choice: choices[0]}, nil
+}
+
+func TestGradePendingDefersAnswerWhoseQuestionFailsToLoad(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	mem := db.NewMemoryStore().WithClock(clock)
+	var answers []string
+	var broken string
+	for _, q := range []db.NewQuestion{
+		{Title: "Capital", Text: "What is the capital of France?", SHA: "capital", Choices: []string{"Paris"}},
+		{Title: "Tallest", Text: "What is the tallest mountain on Earth?", SHA: "tallest", Choices: []string{"Everest"}},
+	} {
+		saved, err := mem.InsertQuestion(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := mem.InsertAnswer(ctx, db.NewAnswer{QuestionID: saved.ID, Text: "Lutetia", Status: db.AnswerPending})
+		if err != nil {
+			t.Fatal(err)
+		}
+		answers, broken = append(answers, a.ID), saved.ID
+	}
+	s := NewQuestionService(brokenQuestion{mem, broken}, verdictGrader{}, nil, nil, log.New(io.Discard, "", 0), Options{Now: clock, GradeRetry: time.Minute})
+	if n, err := s.GradePending(ctx); n != 1 || err != nil {
+		t.Fatalf("pass: %d %v", n, err)
+	}
+	if a, _ := mem.GetAnswer(ctx, answers[0]); a.Status != db.AnswerGraded {
+		t.Fatalf("answer to the loaded question: %+v", a)
+	}
+	a, _ := mem.GetAnswer(ctx, answers[1])
+	if a.Status != db.AnswerPending || a.GradeAttempts != 1 {
+		t.Fatalf("answer to the broken question: %+v", a)
+	}
+	now = now.Add(time.Minute)
+	if claimed, err := mem.ClaimDueAnswers(ctx, now, now.Add(pendingLease), pendingBatch); err != nil || len(claimed) != 1 {
+		t.Fatalf("deferred answer not due after its delay: %v %v", claimed, err)
+	}
+}
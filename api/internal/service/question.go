@@ -3,8 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"strings"
+	"time"
 	"unicode"
 
 	"qotd/api/internal/db"
@@ -16,8 +18,59 @@ var (
 	ErrNoQuestion       = errors.New("no question yet")
 	ErrQuestionNotFound = errors.New("question not found")
 	ErrGenerateFailed   = errors.New("could not generate question")
+	ErrAlreadyPublished = errors.New("question already published for date")
+	ErrAlreadyAnswered  = errors.New("question already answered")
+	ErrPlayerRequired   = errors.New("player token required")
+	// ErrGradingUnavailable wraps llm.ErrUnavailable from the grader.
+	ErrGradingUnavailable = errors.New("grading temporarily unavailable")
 )
 
+// AttemptPolicy decides how repeated answers by one player are treated.
+type AttemptPolicy string
+
+const (
+	// PolicyFirstScored scores a player's first answer; later answers are practice.
+	PolicyFirstScored AttemptPolicy = "first-scored"
+	// PolicySingle allows one answer per player and question.
+	PolicySingle AttemptPolicy = "single"
+)
+
+// Options tunes QuestionService. The zero value is usable.
+type Options struct {
+	// Location sets where a day starts and ends for publish dates; nil means UTC.
+	Location *time.Location
+	// Now overrides the clock, mainly for tests.
+	Now func() time.Time
+	// AttemptPolicy defaults to PolicyFirstScored.
+	AttemptPolicy AttemptPolicy
+	// Tolerance applies to numeric, year and date answers.
+	Tolerance Tolerance
+	// Semantic sets the embedding tier between local matching and the LLM.
+	Semantic SemanticThresholds
+	// GradeCacheSize keeps that many grade verdicts in memory in front of
+	// the store's cache; zero disables the in-process layer. Verdicts are
+	// dropped only by this process, so other replicas may serve stale ones.
+	GradeCacheSize int
+	// RequireReview stores generated questions as drafts, which are not
+	// served until an admin approves them.
+	RequireReview bool
+	// Verifier answers each generated question blind before it is stored;
+	// nil skips the check.
+	Verifier llm.Verifier
+	// GradeRetry stores answers the grader fails on as pending, to be
+	// graded by RunPendingGrader after this delay, doubled per failure up
+	// to an hour. Zero makes SubmitAnswer fail instead.
+	GradeRetry time.Duration
+}
+
+// GenerateOptions controls which day a generated question is published on.
+type GenerateOptions struct {
+	// Date is the publish day; the zero value means today.
+	Date time.Time
+	// Force replaces a question already published on Date.
+	Force bool
+}
+
 type GenerateResult struct {
 	Question   db.Question
 	Choices    []string
@@ -25,47 +78,217 @@ type GenerateResult struct {
 }
 
 type QuestionService struct {
-	repo      *db.Repository
-	grader    *llm.Grader
-	embedder  *llm.Embedder
-	generator *llm.Generator
+	repo      db.QuestionStore
+	grader    llm.Grader
+	embedder  llm.Embedder
+	generator llm.QuestionGenerator
 	logger    *log.Logger
+	loc       *time.Location
+	now       func() time.Time
+	policy    AttemptPolicy
+	tolerance Tolerance
+	semantic  SemanticThresholds
+	verdicts  *verdictLRU
+	review    bool
+	verifier  llm.Verifier
+	// gradeRetry mirrors Options.GradeRetry.
+	gradeRetry time.Duration
 }
 
-func NewQuestionService(repo *db.Repository, grader *llm.Grader, embedder *llm.Embedder, generator *llm.Generator, logger *log.Logger) *QuestionService {
-	return &QuestionService{repo: repo, grader: grader, embedder: embedder, generator: generator, logger: logger}
+func NewQuestionService(repo db.QuestionStore, grader llm.Grader, embedder llm.Embedder, generator llm.QuestionGenerator, logger *log.Logger, opts Options) *QuestionService {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.AttemptPolicy == "" {
+		opts.AttemptPolicy = PolicyFirstScored
+	}
+	return &QuestionService{repo: repo, grader: grader, embedder: embedder, generator: generator, logger: logger, loc: opts.Location, now: opts.Now, policy: opts.AttemptPolicy, tolerance: opts.Tolerance, semantic: opts.Semantic, verdicts: newVerdictLRU(opts.GradeCacheSize), review: opts.RequireReview, verifier: opts.Verifier, gradeRetry: opts.GradeRetry}
+}
+
+// Today returns the current calendar day in the service's timezone, as
+// midnight UTC so it compares equal to stored publish dates.
+func (s *QuestionService) Today() time.Time {
+	return CivilDate(s.now().In(s.loc))
 }
 
+// CivilDate drops the clock and zone from t, keeping its calendar day.
+func CivilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// GetToday returns today's question. When nothing was published for today the
+// oldest backlog question is promoted to today.
 func (s *QuestionService) GetToday(ctx context.Context) (db.Question, error) {
-	q, err := s.repo.GetLatestQuestion(ctx)
+	today := s.Today()
+	q, err := s.GetByDate(ctx, today)
+	if !errors.Is(err, ErrNoQuestion) {
+		return q, err
+	}
+	return s.promote(ctx, today)
+}
+
+// GetByDate returns the question published on day. Future days and drafts
+// awaiting review report ErrNoQuestion so questions are not revealed early.
+func (s *QuestionService) GetByDate(ctx context.Context, day time.Time) (db.Question, error) {
+	day = CivilDate(day)
+	if day.After(s.Today()) {
+		return db.Question{}, ErrNoQuestion
+	}
+	q, err := s.repo.GetQuestionByDate(ctx, day)
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
 			return db.Question{}, ErrNoQuestion
 		}
 		return db.Question{}, err
 	}
+	if q.Status != db.StatusPublished {
+		return db.Question{}, ErrNoQuestion
+	}
 	return q, nil
 }
 
-func (s *QuestionService) SubmitAnswer(ctx context.Context, questionID, answerText string) (int, string, error) {
+// AnswerResult is the outcome of SubmitAnswer. Practice answers were graded
+// but do not count towards the player's record.
+type AnswerResult struct {
+	ID       string
+	Score    int
+	Feedback string
+	Practice bool
+	// Tier names the rule that decided the score.
+	Tier string
+	// Pending answers were stored ungraded because the grader failed.
+	Pending bool
+}
+
+// SubmitAnswer grades and stores an answer. userID is empty for anonymous
+// players, whose answers are always practice. Under PolicyFirstScored a
+// player's first answer counts and later ones are practice; under
+// PolicySingle later ones are rejected with ErrAlreadyAnswered. When the
+// grader fails and Options.GradeRetry is set, the answer is stored as
+// pending instead.
+func (s *QuestionService) SubmitAnswer(ctx context.Context, questionID, userID, answerText string) (AnswerResult, error) {
 	q, err := s.repo.GetQuestionByID(ctx, questionID)
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
-			return 0, "", ErrQuestionNotFound
+			return AnswerResult{}, ErrQuestionNotFound
 		}
-		return 0, "", err
+		return AnswerResult{}, err
+	}
+	if q.Status == db.StatusDraft || q.Status == db.StatusRejected {
+		return AnswerResult{}, ErrQuestionNotFound
+	}
+	practice := userID == ""
+	if practice && s.policy == PolicySingle {
+		return AnswerResult{}, ErrPlayerRequired
 	}
+	if !practice {
+		scored, err := s.repo.HasScoredAnswer(ctx, q.ID, userID)
+		if err != nil {
+			return AnswerResult{}, err
+		}
+		if scored {
+			if s.policy == PolicySingle {
+				return AnswerResult{}, ErrAlreadyAnswered
+			}
+			practice = true
+		}
+	}
+
 	answerText = strings.TrimSpace(answerText)
+	g, err := s.grade(ctx, q, answerText)
+	status := db.AnswerGraded
+	if err != nil {
+		// Only an outage is worth waiting out; a rejected request or a
+		// reply that never parses would fail the same way on every retry.
+		if s.gradeRetry <= 0 || ctx.Err() != nil || !errors.Is(err, ErrGradingUnavailable) {
+			return AnswerResult{}, err
+		}
+		s.logger.Printf("[grade] storing answer to %s as pending: %v", q.ID, err)
+		g, status = grading{feedback: pendingFeedback}, db.AnswerPending
+	}
+	na := db.NewAnswer{QuestionID: q.ID, UserID: userID, Text: answerText, Score: g.score, Feedback: g.feedback, Practice: practice, Provenance: g.prov, Status: status}
+	saved, err := s.repo.InsertAnswer(ctx, na)
+	if errors.Is(err, db.ErrAlreadyAnswered) {
+		// A concurrent submission by the same player was scored first.
+		if s.policy == PolicySingle {
+			return AnswerResult{}, ErrAlreadyAnswered
+		}
+		na.Practice = true
+		saved, err = s.repo.InsertAnswer(ctx, na)
+	}
+	if err != nil {
+		return AnswerResult{}, err
+	}
+	return AnswerResult{ID: saved.ID, Score: saved.Score, Feedback: saved.Feedback, Practice: saved.Practice, Tier: saved.Provenance.Tier, Pending: saved.Status == db.AnswerPending}, nil
+}
+
+// grading is the verdict for one answer.
+type grading struct {
+	score    int
+	feedback string
+	// prov.Tier names the rule that decided: a text.Tier, an answer type,
+	// tierOption, tierSemantic, tierCache or tierLLM.
+	prov db.Provenance
+}
+
+const (
+	tierOption = "option"
+	tierLLM    = "llm"
+)
+
+func (s *QuestionService) grade(ctx context.Context, q db.Question, answerText string) (grading, error) {
+	if g, ok := s.gradeLocal(q, answerText); ok {
+		return g, nil
+	}
+	norm := txt.NormalizeAnswer(answerText)
+	if g, ok := s.cachedVerdict(ctx, q.ID, norm); ok {
+		return g, nil
+	}
+	g, err := s.gradeRemote(ctx, q, answerText)
+	if errors.Is(err, llm.ErrUnavailable) {
+		return grading{}, fmt.Errorf("%w: %w", ErrGradingUnavailable, err)
+	}
+	if err != nil {
+		return grading{}, err
+	}
+	return s.storeVerdict(ctx, q.ID, norm, g), nil
+}
+
+// gradeLocal tries the tiers that need no embedding or LLM call: option
+// labels, typed answers and text matching against the choices.
+func (s *QuestionService) gradeLocal(q db.Question, answerText string) (grading, bool) {
+	if len(q.Options) > 0 {
+		if idx, ok := pickOption(answerText, q.Options); ok {
+			if idx == q.CorrectOption {
+				return grading{score: 10, feedback: "Correct option.", prov: db.Provenance{Tier: tierOption, MatchedChoice: q.Options[idx]}}, true
+			}
+			return grading{score: 0, feedback: "Incorrect option.", prov: db.Provenance{Tier: tierOption, MatchedChoice: q.Options[idx]}}, true
+		}
+	}
+	if g, ok := s.gradeTyped(q, answerText); ok {
+		return g, true
+	}
+	if m, ok := txt.MatchAnswer(answerText, q.Choices); ok {
+		return grading{score: 10, feedback: "Accepted choice.", prov: db.Provenance{Tier: string(m.Tier), MatchedChoice: m.Choice}}, true
+	}
+	return grading{}, false
+}
+
+// gradeRemote decides answers the local tiers could not, by embedding
+// similarity or by asking the LLM grader.
+func (s *QuestionService) gradeRemote(ctx context.Context, q db.Question, answerText string) (grading, error) {
 	if len(q.Choices) > 0 {
-		if matchesChoice(answerText, q.Choices) {
-			const score = 10
-			const feedback = "Accepted choice."
-			return score, feedback, s.repo.InsertAnswer(ctx, questionID, answerText, score, nil, feedback)
+		if g, ok := s.gradeSemantic(ctx, q, answerText); ok {
+			return g, nil
 		}
 		resultScore := 0
 		grade, err := s.grader.Grade(ctx, answerText, q.Choices)
 		if err != nil {
-			return 0, "", err
+			return grading{}, err
 		}
 		feedback := grade.Reason
 		if grade.Match {
@@ -95,12 +318,12 @@ func (s *QuestionService) SubmitAnswer(ctx context.Context, questionID, answerTe
 				feedback = "Accepted choice."
 			}
 		}
-		return resultScore, feedback, s.repo.InsertAnswer(ctx, questionID, answerText, resultScore, nil, feedback)
+		return grading{score: resultScore, feedback: feedback, prov: llmProvenance(grade)}, nil
 	}
 
 	grade, err := s.grader.Grade(ctx, answerText, nil)
 	if err != nil {
-		return 0, "", err
+		return grading{}, err
 	}
 	score := 0
 	feedback := grade.Reason
@@ -113,85 +336,233 @@ func (s *QuestionService) SubmitAnswer(ctx context.Context, questionID, answerTe
 	if feedback == "" {
 		feedback = "Answer not recognized."
 	}
-	return score, feedback, s.repo.InsertAnswer(ctx, questionID, answerText, score, nil, feedback)
+	return grading{score: score, feedback: feedback, prov: llmProvenance(grade)}, nil
+}
+
+func llmProvenance(g llm.GradeResult) db.Provenance {
+	return db.Provenance{
+		Tier:          tierLLM,
+		MatchedChoice: g.Choice,
+		Model:         g.Model,
+		PromptVersion: g.PromptVersion,
+		LatencyMS:     g.Latency.Milliseconds(),
+		RawResponse:   g.Raw,
+	}
 }
 
-func (s *QuestionService) GenerateQuestion(ctx context.Context) (GenerateResult, error) {
+// CreatePlayer registers a new anonymous player.
+func (s *QuestionService) CreatePlayer(ctx context.Context) (db.Player, error) {
+	return s.repo.CreatePlayer(ctx)
+}
+
+func (s *QuestionService) GenerateQuestion(ctx context.Context, opts GenerateOptions) (GenerateResult, error) {
+	day, err := s.generateDay(ctx, opts)
+	if err != nil {
+		return GenerateResult{}, err
+	}
+	return s.generateFor(ctx, day, opts.Force, nil)
+}
+
+// generateDay resolves the publish day of opts and, unless forced, checks
+// that it has no question yet.
+func (s *QuestionService) generateDay(ctx context.Context, opts GenerateOptions) (time.Time, error) {
+	day := s.Today()
+	if !opts.Date.IsZero() {
+		day = CivilDate(opts.Date)
+	}
+	if !opts.Force {
+		if _, err := s.repo.GetQuestionByDate(ctx, day); err == nil {
+			return time.Time{}, ErrAlreadyPublished
+		} else if !errors.Is(err, db.ErrNotFound) {
+			return time.Time{}, err
+		}
+	}
+	return day, nil
+}
+
+// generateFor generates and stores the question for day, passing each
+// attempt to record when it is not nil.
+func (s *QuestionService) generateFor(ctx context.Context, day time.Time, force bool, record func(db.JobAttempt)) (GenerateResult, error) {
+	c, err := s.generateCandidate(ctx, record)
+	if err != nil {
+		return GenerateResult{}, err
+	}
+	c.question.PublishDate = &day
+	c.question.Status = s.generatedStatus(db.StatusPublished)
+	// A draft replacement waits beside the live question and supersedes it
+	// once approved.
+	c.question.Supersede = force && c.question.Status != db.StatusDraft
+	saved, err := s.repo.InsertQuestion(ctx, c.question)
+	if err != nil {
+		if errors.Is(err, db.ErrDateTaken) {
+			return GenerateResult{}, ErrAlreadyPublished
+		}
+		return GenerateResult{}, err
+	}
+	s.logger.Printf("[generate] inserted question id=%s date=%s status=%s sim=%.3f", saved.ID, day.Format(time.DateOnly), saved.Status, c.similarity)
+	return GenerateResult{Question: saved, Choices: saved.Choices, Similarity: c.similarity}, nil
+}
+
+// candidate is a generated question that passed every duplicate check.
+type candidate struct {
+	question   db.NewQuestion
+	similarity float64
+}
+
+// Reasons a generated candidate was rejected, as recorded in job attempts.
+const (
+	rejectLLMError        = "llm_error"
+	rejectInvalidOutput   = "invalid_output"
+	rejectLength          = "length"
+	rejectInvalidOptions  = "invalid_options"
+	rejectNoChoices       = "no_choices"
+	rejectChoiceOverlap   = "choice_overlap"
+	rejectChoiceSignature = "choice_signature"
+	rejectDuplicateSHA    = "duplicate_sha"
+	rejectEmbedError      = "embed_error"
+	rejectSimilarity      = "similarity"
+	rejectVerification    = "verification"
+)
+
+// generateCandidate asks the LLM for questions until one passes validation
+// and duplicate checks, or the attempts run out. Every attempt, rejected or
+// accepted, is passed to record when it is not nil.
+func (s *QuestionService) generateCandidate(ctx context.Context, record func(db.JobAttempt)) (candidate, error) {
 	const maxTries = 5
+	if record == nil {
+		record = func(db.JobAttempt) {}
+	}
+	invalid := 0
 	for i := 0; i < maxTries; i++ {
 		s.logger.Printf("[generate] attempt %d/%d", i+1, maxTries)
+		attempt := db.JobAttempt{Number: i + 1}
+		reject := func(reason, format string, args ...any) {
+			attempt.Reason, attempt.Detail = reason, fmt.Sprintf(format, args...)
+			s.logger.Printf("[generate] %s", attempt.Detail)
+			record(attempt)
+		}
 		q, err := s.generator.GenerateQuestion(ctx)
+		var verr *llm.ValidationError
+		if errors.As(err, &verr) {
+			invalid++
+			attempt.Title, attempt.Text = q.Title, q.Text
+			reject(invalidReason(verr, q), "%v", err)
+			continue
+		}
 		if err != nil {
-			s.logger.Printf("[generate] llm error: %v", err)
+			reject(rejectLLMError, "llm error: %v", err)
 			continue
 		}
-		if len(q.Text) < 20 || len(q.Text) > 400 {
-			s.logger.Printf("[generate] text length out of range: %d", len(q.Text))
+		attempt.Title, attempt.Text = q.Title, q.Text
+		if !validQuestionText(q.Text) {
+			reject(rejectLength, "text length out of range: %d", len(q.Text))
 			continue
 		}
+		var options []string
+		correctOption := 0
+		if q.Correct != "" || len(q.Distractors) > 0 {
+			options, correctOption, q.Choices, err = buildOptions(q)
+			if err != nil {
+				reject(rejectInvalidOptions, "invalid options: %v", err)
+				continue
+			}
+		}
 		if len(q.Choices) == 0 {
-			s.logger.Printf("[generate] no choices returned")
+			reject(rejectNoChoices, "no choices returned")
 			continue
 		}
-		normalizedChoices := txt.NormalizedChoices(q.Choices)
-		choiceSig := txt.ChoiceSignature(q.Choices)
+		kind := answerType(q.AnswerType, q.Choices)
+		if q.AnswerType != "" && kind != q.AnswerType {
+			s.logger.Printf("[generate] choices do not parse as %s; grading as %s", q.AnswerType, kind)
+		}
+		keys := choiceKeys(q.Choices)
+		normalizedChoices, choiceSig := keys.Normalized, keys.ChoiceSig
 		if len(normalizedChoices) > 0 {
-			overlap, err := s.repo.HasChoiceOverlap(ctx, normalizedChoices)
+			overlap, err := s.repo.HasChoiceOverlap(ctx, normalizedChoices, "")
 			if err != nil {
-				return GenerateResult{}, err
+				return candidate{}, err
 			}
 			if overlap {
-				s.logger.Printf("[generate] duplicate choices overlap detected")
+				reject(rejectChoiceOverlap, "duplicate choices overlap detected")
 				continue
 			}
 		}
 		if choiceSig != "" {
 			exists, err := s.repo.ExistsQuestionByChoiceSignature(ctx, choiceSig)
 			if err != nil {
-				return GenerateResult{}, err
+				return candidate{}, err
 			}
 			if exists {
-				s.logger.Printf("[generate] duplicate choice signature detected")
+				reject(rejectChoiceSignature, "duplicate choice signature detected")
 				continue
 			}
 		}
-		norm := txt.NormalizeQuestion(q.Text)
-		sha := txt.SHA256Hex(norm)
+		sha := questionSHA(q.Text)
 		exists, err := s.repo.ExistsQuestionBySHA(ctx, sha)
 		if err != nil {
-			return GenerateResult{}, err
+			return candidate{}, err
 		}
 		if exists {
-			s.logger.Printf("[generate] duplicate sha detected")
+			reject(rejectDuplicateSHA, "duplicate sha detected")
 			continue
 		}
 
-		emb, err := s.embedder.Embed(ctx, q.Text)
-		if err != nil || len(emb) == 0 {
-			if err != nil {
-				s.logger.Printf("[generate] embed error: %v", err)
-			} else {
-				s.logger.Printf("[generate] embed empty result")
-			}
+		emb, err := s.embedQuestion(ctx, q.Text)
+		if err != nil {
+			reject(rejectEmbedError, "%v", err)
 			continue
 		}
-		maxSim, err := s.repo.MaxSimilarity(ctx, emb)
+		maxSim, err := s.repo.MaxSimilarity(ctx, emb, "")
 		if err != nil {
-			return GenerateResult{}, err
+			return candidate{}, err
 		}
-		if maxSim >= 0.6 {
-			s.logger.Printf("[generate] too similar: sim=%.3f", maxSim)
+		attempt.Similarity = &maxSim
+		if maxSim >= maxSimilarity {
+			reject(rejectSimilarity, "too similar: sim=%.3f", maxSim)
 			continue
 		}
-
-		saved, err := s.repo.InsertQuestion(ctx, q.Title, q.Text, q.Topic, sha, emb, q.Choices, normalizedChoices, choiceSig)
+		verification, err := s.verify(ctx, q, kind)
 		if err != nil {
-			return GenerateResult{}, err
+			reject(rejectVerification, "%v", err)
+			continue
 		}
-		s.logger.Printf("[generate] inserted question id=%s sim=%.3f", saved.ID, maxSim)
-		return GenerateResult{Question: saved, Choices: q.Choices, Similarity: maxSim}, nil
+		record(attempt)
+
+		return candidate{
+			question: db.NewQuestion{
+				Title:            q.Title,
+				Text:             q.Text,
+				Topic:            q.Topic,
+				SHA:              sha,
+				Embedding:        emb,
+				Choices:          q.Choices,
+				ChoiceEmbeddings: s.embedChoices(ctx, q.Choices),
+				Normalized:       normalizedChoices,
+				ChoiceSig:        choiceSig,
+				Options:          options,
+				CorrectOption:    correctOption,
+				AnswerType:       string(kind),
+				Verification:     verification,
+			},
+			similarity: maxSim,
+		}, nil
+	}
+	if invalid > 0 {
+		s.logger.Printf("[generate] %d/%d attempts returned invalid output", invalid, maxTries)
 	}
-	return GenerateResult{}, ErrGenerateFailed
+	return candidate{}, ErrGenerateFailed
+}
+
+// invalidReason files output the generator rejected under the service check
+// that would have caught it, so an attempt reads the same either way.
+func invalidReason(e *llm.ValidationError, q llm.Question) string {
+	switch {
+	case e.Field == "text":
+		return rejectLength
+	case e.Field == "choices" && len(q.Choices) == 0:
+		return rejectNoChoices
+	}
+	return rejectInvalidOutput
 }
 
 func matchesChoice(input string, choices []string) bool {
@@ -206,22 +577,6 @@ func matchesChoice(input string, choices []string) bool {
 			}
 		}
 	}
-	trimmed := strings.TrimSpace(strings.ToLower(input))
-	if trimmed == "" {
-		return false
-	}
-	labelCandidate := strings.Trim(trimmed, "(). ")
-	if len(labelCandidate) == 1 {
-		firstRune := []rune(labelCandidate)[0]
-		if firstRune >= 'a' && firstRune <= 'z' {
-			idx := int(firstRune - 'a')
-			return idx >= 0 && idx < len(choices)
-		}
-		if firstRune >= '1' && firstRune <= '9' {
-			idx := int(firstRune - '1')
-			return idx >= 0 && idx < len(choices)
-		}
-	}
 	return false
 }
 
@@ -241,3 +596,37 @@ func normalizeAnswer(s string) string {
 	}
 	return b.String()
 }
+
+const (
+	minQuestionText = llm.MinQuestionText
+	maxQuestionText = llm.MaxQuestionText
+	// maxSimilarity rejects a question text whose embedding is at least this
+	// close to another question's.
+	maxSimilarity = 0.6
+)
+
+func validQuestionText(text string) bool {
+	return len(text) >= minQuestionText && len(text) <= maxQuestionText
+}
+
+// questionSHA is the duplicate key of a question's text.
+func questionSHA(text string) string {
+	return txt.SHA256Hex(txt.NormalizeQuestion(text))
+}
+
+// choiceKeys derives the stored forms of a question's choices, without
+// their embeddings.
+func choiceKeys(choices []string) db.ChoiceUpdate {
+	return db.ChoiceUpdate{Choices: choices, Normalized: txt.NormalizedChoices(choices), ChoiceSig: txt.ChoiceSignature(choices)}
+}
+
+func (s *QuestionService) embedQuestion(ctx context.Context, text string) ([]float32, error) {
+	emb, err := s.embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("embed error: %w", err)
+	}
+	if len(emb) == 0 {
+		return nil, errors.New("embed empty result")
+	}
+	return emb, nil
+}
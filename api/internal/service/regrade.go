@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"qotd/api/internal/db"
+	txt "qotd/api/internal/text"
+)
+
+// MaxRegradeDays caps the date range of one regrade.
+const MaxRegradeDays = 366
+
+var ErrRegradeScope = errors.New("regrade needs a question id or a date range")
+
+// RegradeScope selects the answers to regrade: every answer to QuestionID,
+// or to the questions published From through To.
+type RegradeScope struct {
+	QuestionID string
+	From, To   time.Time
+	// Apply stores the new scores; otherwise the regrade is a dry run.
+	Apply bool
+}
+
+// RegradeChange is one answer whose score would change, or did.
+type RegradeChange struct {
+	AnswerID   string
+	QuestionID string
+	Text       string
+	OldScore   int
+	NewScore   int
+	OldTier    string
+	NewTier    string
+	Feedback   string
+}
+
+// RegradeReport summarizes a regrade.
+type RegradeReport struct {
+	Applied   bool
+	Questions int
+	Answers   int
+	Changes   []RegradeChange
+	// Skipped counts answers decided on appeal, which a regrade keeps.
+	Skipped int
+	// Failed counts answers the grader could not grade; they keep their score.
+	Failed int
+}
+
+// Regrade replays grading over stored answers with the question's current
+// choices and grader, bypassing the verdict cache. Identical answers to one
+// question are graded once. With Apply, answers whose score changed are
+// updated and the question's cached verdicts are replaced.
+func (s *QuestionService) Regrade(ctx context.Context, scope RegradeScope) (RegradeReport, error) {
+	questions, err := s.regradeQuestions(ctx, scope)
+	if err != nil {
+		return RegradeReport{}, err
+	}
+	report := RegradeReport{Applied: scope.Apply, Questions: len(questions), Changes: []RegradeChange{}}
+	for _, q := range questions {
+		if err := s.regradeQuestion(ctx, q, scope.Apply, &report); err != nil {
+			return report, err
+		}
+	}
+	if scope.Apply && len(report.Changes) > 0 {
+		if err := s.repo.RefreshLeaderboard(ctx); err != nil {
+			s.logger.Printf("[regrade] leaderboard refresh: %v", err)
+		}
+	}
+	return report, nil
+}
+
+func (s *QuestionService) regradeQuestions(ctx context.Context, scope RegradeScope) ([]db.Question, error) {
+	if scope.QuestionID != "" {
+		q, err := s.repo.GetQuestionByID(ctx, scope.QuestionID)
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrQuestionNotFound
+		}
+		return []db.Question{q}, err
+	}
+	if scope.From.IsZero() || scope.To.IsZero() {
+		return nil, ErrRegradeScope
+	}
+	from, to := CivilDate(scope.From), CivilDate(scope.To)
+	if to.Before(from) || to.Sub(from) >= MaxRegradeDays*24*time.Hour {
+		return nil, ErrRegradeScope
+	}
+	days, err := s.repo.ListPublishDates(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	questions := make([]db.Question, 0, len(days))
+	for _, day := range days {
+		q, err := s.repo.GetQuestionByDate(ctx, day)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+	return questions, nil
+}
+
+func (s *QuestionService) regradeQuestion(ctx context.Context, q db.Question, apply bool, report *RegradeReport) error {
+	answers, err := s.repo.ListAnswers(ctx, q.ID)
+	if err != nil {
+		return err
+	}
+	remote := map[string]grading{}
+	for _, a := range answers {
+		report.Answers++
+		if a.Provenance.Tier == tierDispute {
+			report.Skipped++
+			continue
+		}
+		g, ok := s.gradeLocal(q, a.Text)
+		if !ok {
+			norm := txt.NormalizeAnswer(a.Text)
+			if g, ok = remote[norm]; !ok {
+				if g, err = s.gradeRemote(ctx, q, a.Text); err != nil {
+					s.logger.Printf("[regrade] answer %s: %v", a.ID, err)
+					report.Failed++
+					continue
+				}
+				remote[norm] = g
+			}
+		}
+		if g.score == a.Score {
+			continue
+		}
+		report.Changes = append(report.Changes, RegradeChange{
+			AnswerID:   a.ID,
+			QuestionID: q.ID,
+			Text:       a.Text,
+			OldScore:   a.Score,
+			NewScore:   g.score,
+			OldTier:    a.Provenance.Tier,
+			NewTier:    g.prov.Tier,
+			Feedback:   g.feedback,
+		})
+		if apply {
+			if err := s.repo.UpdateAnswerGrade(ctx, a.ID, g.score, g.feedback, g.prov); err != nil {
+				return err
+			}
+		}
+	}
+	if !apply {
+		return nil
+	}
+	if err := s.repo.DeleteGradeVerdicts(ctx, q.ID); err != nil {
+		return err
+	}
+	s.verdicts.dropQuestion(q.ID)
+	for norm, g := range remote {
+		s.storeVerdict(ctx, q.ID, norm, g)
+	}
+	return nil
+}
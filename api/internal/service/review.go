@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"qotd/api/internal/db"
+)
+
+var ErrNotReviewable = errors.New("question is not awaiting review")
+
+// generatedStatus is the status of a newly generated question: a draft when
+// review is required, otherwise live.
+func (s *QuestionService) generatedStatus(live string) string {
+	if s.review {
+		return db.StatusDraft
+	}
+	return live
+}
+
+// ApproveQuestion approves a draft. A draft generated for a date is published
+// on it; a queued draft becomes eligible for promotion from the backlog.
+func (s *QuestionService) ApproveQuestion(ctx context.Context, id, note string) (db.Question, error) {
+	q, err := s.repo.ApproveQuestion(ctx, id, strings.TrimSpace(note))
+	return q, reviewError(err)
+}
+
+// RejectQuestion rejects a draft or an unpublished approved question. Its
+// publish date or backlog place is released.
+func (s *QuestionService) RejectQuestion(ctx context.Context, id, note string) (db.Question, error) {
+	q, err := s.repo.RejectQuestion(ctx, id, strings.TrimSpace(note))
+	return q, reviewError(err)
+}
+
+func reviewError(err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return ErrQuestionNotFound
+	case errors.Is(err, db.ErrNotReviewable):
+		return ErrNotReviewable
+	}
+	return err
+}
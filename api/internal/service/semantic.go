@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"qotd/api/internal/db"
+)
+
+const tierSemantic = "semantic"
+
+// SemanticThresholds grade answers by embedding similarity to the closest
+// accepted choice. Answers at or above Accept are correct, answers below
+// Reject are wrong, and the band in between goes to the LLM grader. A zero
+// Accept turns the tier off.
+type SemanticThresholds struct {
+	Accept float64
+	Reject float64
+}
+
+// embedChoices embeds each accepted choice. It returns nil if any embedding
+// fails, which leaves the question without a semantic tier.
+func (s *QuestionService) embedChoices(ctx context.Context, choices []string) [][]float32 {
+	out := make([][]float32, 0, len(choices))
+	for _, c := range choices {
+		emb, err := s.embedder.Embed(ctx, c)
+		if err != nil || len(emb) == 0 {
+			s.logger.Printf("[generate] choice embed failed for %q: %v", c, err)
+			return nil
+		}
+		out = append(out, emb)
+	}
+	return out
+}
+
+// gradeSemantic decides answers that are clearly close to or far from every
+// accepted choice. It reports false when the tier is off, the question has no
+// choice embeddings, or the similarity falls between the thresholds.
+func (s *QuestionService) gradeSemantic(ctx context.Context, q db.Question, answerText string) (grading, bool) {
+	if s.semantic.Accept == 0 {
+		return grading{}, false
+	}
+	emb, err := s.embedder.Embed(ctx, answerText)
+	if err != nil || len(emb) == 0 {
+		s.logger.Printf("[grade] answer embed failed: %v", err)
+		return grading{}, false
+	}
+	m, err := s.repo.NearestChoice(ctx, q.ID, emb)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			s.logger.Printf("[grade] nearest choice: %v", err)
+		}
+		return grading{}, false
+	}
+	prov := db.Provenance{Tier: tierSemantic, MatchedChoice: m.Choice, Similarity: m.Similarity}
+	switch {
+	case m.Similarity >= s.semantic.Accept:
+		return grading{score: 10, feedback: "Accepted choice.", prov: prov}, true
+	case m.Similarity < s.semantic.Reject:
+		prov.MatchedChoice = ""
+		return grading{score: 0, feedback: "Answer not recognized as acceptable.", prov: prov}, true
+	}
+	return grading{}, false
+}
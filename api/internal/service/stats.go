@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"qotd/api/internal/db"
+)
+
+// PlayerStats summarises a player's scored answers.
+type PlayerStats struct {
+	Answered int
+	Correct  int
+	// CurrentStreak counts consecutive published days, ending today or the
+	// previous published day, that the player answered correctly.
+	CurrentStreak int
+	LongestStreak int
+}
+
+// History returns up to limit of the player's scored answers, newest first.
+func (s *QuestionService) History(ctx context.Context, userID string, limit int) ([]db.HistoryEntry, error) {
+	entries, err := s.repo.ListPlayerHistory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// Stats computes answer counts and streaks for a player.
+func (s *QuestionService) Stats(ctx context.Context, userID string) (PlayerStats, error) {
+	entries, err := s.repo.ListPlayerHistory(ctx, userID)
+	if err != nil {
+		return PlayerStats{}, err
+	}
+	if len(entries) == 0 {
+		return PlayerStats{}, nil
+	}
+	var stats PlayerStats
+	answered := make(map[string]bool, len(entries))
+	correct := make(map[string]bool, len(entries))
+	first := *entries[0].Question.PublishDate
+	for _, e := range entries {
+		// Pending answers count once graded.
+		if e.Answer.Status == db.AnswerPending {
+			continue
+		}
+		stats.Answered++
+		day := e.Question.PublishDate.Format(time.DateOnly)
+		answered[day] = true
+		if e.Answer.Score > 0 {
+			stats.Correct++
+			correct[day] = true
+		}
+		if e.Question.PublishDate.Before(first) {
+			first = *e.Question.PublishDate
+		}
+	}
+	today := s.Today()
+	dates, err := s.repo.ListPublishDates(ctx, first, today)
+	if err != nil {
+		return PlayerStats{}, err
+	}
+	stats.CurrentStreak, stats.LongestStreak = streaks(dates, answered, correct, today)
+	return stats, nil
+}
+
+// streaks walks publish dates (ascending). A day without a question does not
+// break a streak; a published day answered wrongly or skipped does, except
+// today, which may still be answered.
+func streaks(dates []time.Time, answered, correct map[string]bool, today time.Time) (current, longest int) {
+	run := 0
+	for _, d := range dates {
+		if correct[d.Format(time.DateOnly)] {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	i := len(dates) - 1
+	if i >= 0 && dates[i].Equal(today) && !answered[today.Format(time.DateOnly)] {
+		i--
+	}
+	for ; i >= 0 && correct[dates[i].Format(time.DateOnly)]; i-- {
+		current++
+	}
+	return current, longest
+}
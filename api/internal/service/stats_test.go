@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreaks(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	set := func(days ...int) map[string]bool {
+		m := map[string]bool{}
+		for _, d := range days {
+			m[day(d).Format(time.DateOnly)] = true
+		}
+		return m
+	}
+	published := []time.Time{day(1), day(2), day(3), day(5), day(6), day(7), day(8)}
+	cases := []struct {
+		name              string
+		answered, correct map[string]bool
+		today             time.Time
+		current, longest  int
+	}{
+		{"gap day does not break", set(1, 2, 3, 5, 6, 7), set(1, 2, 3, 5, 6, 7), day(8), 6, 6},
+		{"today answered", set(5, 6, 7, 8), set(5, 6, 7, 8), day(8), 4, 4},
+		{"today answered wrong", set(6, 7, 8), set(6, 7), day(8), 0, 2},
+		{"missed day breaks", set(1, 2, 3, 6, 7), set(1, 2, 3, 6, 7), day(8), 2, 3},
+		{"stale streak", set(1, 2), set(1, 2), day(8), 0, 2},
+	}
+	for _, c := range cases {
+		current, longest := streaks(published, c.answered, c.correct, c.today)
+		if current != c.current || longest != c.longest {
+			t.Errorf("%s: got current=%d longest=%d want %d/%d", c.name, current, longest, c.current, c.longest)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"qotd/api/internal/db"
+	"qotd/api/internal/llm"
+)
+
+// verify asks the verifier to answer a candidate blind and accepts the
+// candidate only if that answer is one of its choices after normalization,
+// or for typed questions the same value. Without a verifier every candidate
+// passes unrecorded.
+func (s *QuestionService) verify(ctx context.Context, q llm.Question, kind llm.AnswerType) (*db.Verification, error) {
+	if s.verifier == nil {
+		return nil, nil
+	}
+	blind, err := s.verifier.AnswerQuestion(ctx, q.Text)
+	if err != nil {
+		return nil, fmt.Errorf("verify error: %w", err)
+	}
+	matched, ok := s.blindMatch(blind.Answer, q.Choices, kind)
+	if !ok {
+		return nil, fmt.Errorf("verification failed: answered %q, choices %q", blind.Answer, q.Choices)
+	}
+	return &db.Verification{
+		Verdict:       db.VerificationPassed,
+		Answer:        blind.Answer,
+		MatchedChoice: matched,
+		Model:         blind.Model,
+		PromptVersion: llm.VerifyPromptVersion,
+	}, nil
+}
+
+// blindMatch returns the choice a blind answer agrees with. Numbers, years
+// and dates are compared within the grading tolerance, since "8,849 m" and
+// "8849 metres" are the same answer.
+func (s *QuestionService) blindMatch(answer string, choices []string, kind llm.AnswerType) (string, bool) {
+	norm := normalizeAnswer(answer)
+	if norm == "" {
+		return "", false
+	}
+	for _, c := range choices {
+		if norm == normalizeAnswer(c) {
+			return c, true
+		}
+	}
+	for _, c := range choices {
+		if g, ok := s.gradeTyped(db.Question{Choices: []string{c}, AnswerType: string(kind)}, answer); ok && g.score == 10 {
+			return c, true
+		}
+	}
+	return "", false
+}
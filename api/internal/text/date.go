@@ -0,0 +1,44 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ordinalSuffix = regexp.MustCompile(`\b(\d{1,2})(st|nd|rd|th)\b`)
+	dateLayouts   = []string{
+		"2006-01-02",
+		"2006/01/02",
+		"January 2 2006",
+		"Jan 2 2006",
+		"2 January 2006",
+		"2 Jan 2006",
+	}
+)
+
+// ParseDate reads a calendar date such as "1969-07-20", "July 20th, 1969" or
+// "20 Jul 1969" and returns it as midnight UTC.
+func ParseDate(s string) (time.Time, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "on ")
+	s = ordinalSuffix.ReplaceAllString(s, "$1")
+	var words []string
+	for _, w := range strings.Fields(strings.NewReplacer(",", " ", ".", " ").Replace(s)) {
+		switch w {
+		case "the", "of":
+			continue
+		case "sept":
+			w = "sep"
+		}
+		words = append(words, w)
+	}
+	s = strings.Join(words, " ")
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
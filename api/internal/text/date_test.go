@@ -0,0 +1,24 @@
+package text
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(1969, 7, 20, 0, 0, 0, 0, time.UTC)
+	for _, in := range []string{"1969-07-20", "July 20, 1969", "july 20th 1969", "20 Jul 1969", "the 20th of July, 1969", "on Jul. 20, 1969"} {
+		got, ok := ParseDate(in)
+		if !ok || !got.Equal(want) {
+			t.Fatalf("ParseDate(%q) = %v, %v", in, got, ok)
+		}
+	}
+	if got, ok := ParseDate("Sept 1, 1939"); !ok || got.Month() != time.September {
+		t.Fatalf("ParseDate(Sept) = %v, %v", got, ok)
+	}
+	for _, in := range []string{"", "July 1969", "1969", "yesterday"} {
+		if got, ok := ParseDate(in); ok {
+			t.Fatalf("ParseDate(%q) = %v, want failure", in, got)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package text
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Tier names the local matching rule that accepted an answer, from strictest
+// to loosest.
+type Tier string
+
+const (
+	TierExact        Tier = "exact"
+	TierFolded       Tier = "folded"
+	TierTokenSet     Tier = "token_set"
+	TierEditDistance Tier = "edit_distance"
+	TierPhonetic     Tier = "phonetic"
+)
+
+// Match is an answer accepted by MatchAnswer.
+type Match struct {
+	Tier   Tier
+	Choice string
+}
+
+var (
+	stopwords = map[string]bool{"the": true, "a": true, "an": true, "of": true, "and": true}
+	// ligatures covers letters that do not decompose into a base letter and
+	// a combining mark.
+	ligatures = strings.NewReplacer("ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "ł", "l", "đ", "d", "ð", "d", "þ", "th", "ı", "i")
+)
+
+// MatchAnswer compares an answer with each accepted choice, trying the
+// stricter tiers first. Answers or choices containing digits only match
+// exactly, after folding or as a token set, so "1969" never matches "1968".
+func MatchAnswer(answer string, choices []string) (Match, bool) {
+	for _, tier := range []Tier{TierExact, TierFolded, TierTokenSet, TierEditDistance, TierPhonetic} {
+		for _, c := range choices {
+			if matchTier(tier, answer, c) {
+				return Match{Tier: tier, Choice: c}, true
+			}
+		}
+	}
+	return Match{}, false
+}
+
+func matchTier(tier Tier, answer, choice string) bool {
+	switch tier {
+	case TierExact:
+		a := NormalizeAnswer(answer)
+		return a != "" && a == NormalizeAnswer(choice)
+	case TierFolded:
+		a := NormalizeAnswer(Fold(answer))
+		return a != "" && a == NormalizeAnswer(Fold(choice))
+	case TierTokenSet:
+		a := tokenSet(answer)
+		return a != "" && a == tokenSet(choice)
+	}
+	a, c := NormalizeAnswer(Fold(answer)), NormalizeAnswer(Fold(choice))
+	if a == "" || c == "" || hasDigit(a) || hasDigit(c) {
+		return false
+	}
+	n := len([]rune(c))
+	switch tier {
+	case TierEditDistance:
+		allowed := editAllowance(n)
+		return closeSpelling(a, c, allowed) ||
+			closeSpelling(tokenSet(answer), tokenSet(choice), allowed)
+	case TierPhonetic:
+		// Metaphone drops vowels, so the first vowel must agree as well or
+		// "Persia" would match "Prussia".
+		if n < 4 || firstVowel(a) != firstVowel(c) || DamerauLevenshtein(a, c) > n/2 {
+			return false
+		}
+		ka, kc := phoneticKey(answer), phoneticKey(choice)
+		return len(kc) >= 3 && ka == kc
+	}
+	return false
+}
+
+// closeSpelling reports whether a is within allowed edits of c. Typos rarely
+// hit the first letter or change the length by more than one, while "Haiti"
+// and "Tahiti" or "Austria" and "Australia" do.
+func closeSpelling(a, c string, allowed int) bool {
+	ra, rc := []rune(a), []rune(c)
+	if allowed == 0 || len(ra) == 0 || len(rc) == 0 || ra[0] != rc[0] || abs(len(ra)-len(rc)) > 1 {
+		return false
+	}
+	return DamerauLevenshtein(a, c) <= allowed
+}
+
+// editAllowance is how many edits an answer may be from a choice of n runes.
+func editAllowance(n int) int {
+	switch {
+	case n <= 4:
+		return 0
+	case n <= 7:
+		return 1
+	case n <= 11:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Fold lowercases s and strips diacritics: "Pelé" and "Dvořák" become "pele"
+// and "dvorak".
+func Fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, strings.ToLower(s))
+	if err != nil {
+		folded = strings.ToLower(s)
+	}
+	return ligatures.Replace(folded)
+}
+
+// tokenSet returns the sorted, unique words of s without articles, so word
+// order and punctuation do not matter: "Armstrong, Neil" and "Neil Armstrong"
+// agree.
+func tokenSet(s string) string {
+	words := strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := map[string]bool{}
+	var tokens []string
+	for _, w := range words {
+		if stopwords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		tokens = append(tokens, w)
+	}
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+func phoneticKey(s string) string {
+	var keys []string
+	for _, w := range strings.Fields(tokenSet(s)) {
+		keys = append(keys, Metaphone(w))
+	}
+	return strings.Join(keys, " ")
+}
+
+// firstVowel returns the first vowel of s, counting "y" as "i".
+func firstVowel(s string) rune {
+	for _, r := range s {
+		switch r {
+		case 'a', 'e', 'i', 'o', 'u':
+			return r
+		case 'y':
+			return 'i'
+		}
+	}
+	return 0
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
+
+// DamerauLevenshtein counts the insertions, deletions, substitutions and
+// adjacent transpositions needed to turn a into b (optimal string alignment).
+func DamerauLevenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
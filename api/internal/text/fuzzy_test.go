@@ -0,0 +1,89 @@
+package text
+
+import "testing"
+
+func TestMatchAnswerTiers(t *testing.T) {
+	cases := []struct {
+		answer  string
+		choices []string
+		tier    Tier
+	}{
+		{"the Beatles", []string{"Beatles"}, TierExact},
+		{"Pele", []string{"Pelé"}, TierFolded},
+		{"Dvorak", []string{"Antonín Dvořák", "Dvořák"}, TierFolded},
+		{"Armstrong, Neil", []string{"Neil Armstrong"}, TierTokenSet},
+		{"Eisenhowr", []string{"Eisenhower"}, TierEditDistance},
+		{"Armstorng, Neil", []string{"Neil Armstrong"}, TierEditDistance},
+		{"Tchaikovsky", []string{"Tchaikowsky"}, TierEditDistance},
+		{"Nietzche", []string{"Nietzsche"}, TierEditDistance},
+		{"Philadelfia", []string{"Philadelphia"}, TierEditDistance},
+		{"Smythe", []string{"Smith"}, TierPhonetic},
+		{"Fillip", []string{"Philip"}, TierPhonetic},
+	}
+	for _, c := range cases {
+		m, ok := MatchAnswer(c.answer, c.choices)
+		if !ok || m.Tier != c.tier {
+			t.Fatalf("MatchAnswer(%q, %v) = %+v, %v; want tier %s", c.answer, c.choices, m, ok, c.tier)
+		}
+	}
+}
+
+func TestMatchAnswerRejects(t *testing.T) {
+	cases := []struct {
+		answer  string
+		choices []string
+	}{
+		{"", []string{"Paris"}},
+		{"Lyon", []string{"Paris"}},
+		{"Mali", []string{"Bali"}},
+		{"Haiti", []string{"Tahiti"}},
+		{"1968", []string{"1969"}},
+		{"Apollo 12", []string{"Apollo 11"}},
+		{"Persia", []string{"Prussia"}},
+		{"Austria", []string{"Australia"}},
+		{"Neil", []string{"Neil Armstrong"}},
+	}
+	for _, c := range cases {
+		if m, ok := MatchAnswer(c.answer, c.choices); ok {
+			t.Fatalf("MatchAnswer(%q, %v) = %+v, want no match", c.answer, c.choices, m)
+		}
+	}
+}
+
+func TestDamerauLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"ca", "ac", 1},
+		{"armstrong", "armstorng", 1},
+		{"dvořák", "dvorak", 2},
+	}
+	for _, c := range cases {
+		if got := DamerauLevenshtein(c.a, c.b); got != c.want {
+			t.Fatalf("DamerauLevenshtein(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestMetaphone(t *testing.T) {
+	cases := map[string]string{
+		"Smith":     "SM0",
+		"Thompson":  "0MPSN",
+		"knight":    "NT",
+		"Philip":    "FLP",
+		"Schmidt":   "SKMTT",
+		"Xavier":    "SFR",
+		"Wright":    "RT",
+		"Catherine": "K0RN",
+		"Kathryn":   "K0RN",
+	}
+	for in, want := range cases {
+		if got := Metaphone(in); got != want {
+			t.Fatalf("Metaphone(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
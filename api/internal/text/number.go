@@ -0,0 +1,303 @@
+package text
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Quantity is a number read from an answer. Value is in the base unit of Dim
+// (metres, kilograms, seconds) when a known unit follows the number; Raw is
+// the number as written.
+type Quantity struct {
+	Value float64
+	Raw   float64
+	Dim   string
+}
+
+type unit struct {
+	dim    string
+	factor float64
+}
+
+var units = map[string]unit{
+	"mm": {"length", 0.001}, "millimetre": {"length", 0.001}, "millimeter": {"length", 0.001},
+	"cm": {"length", 0.01}, "centimetre": {"length", 0.01}, "centimeter": {"length", 0.01},
+	"m": {"length", 1}, "metre": {"length", 1}, "meter": {"length", 1},
+	"km": {"length", 1000}, "kilometre": {"length", 1000}, "kilometer": {"length", 1000},
+	"in": {"length", 0.0254}, "inch": {"length", 0.0254},
+	"ft": {"length", 0.3048}, "foot": {"length", 0.3048}, "feet": {"length", 0.3048},
+	"yd": {"length", 0.9144}, "yard": {"length", 0.9144},
+	"mi": {"length", 1609.344}, "mile": {"length", 1609.344},
+	"mg": {"mass", 1e-6}, "milligram": {"mass", 1e-6},
+	"g": {"mass", 0.001}, "gram": {"mass", 0.001},
+	"kg": {"mass", 1}, "kilogram": {"mass", 1},
+	"t": {"mass", 1000}, "tonne": {"mass", 1000}, "ton": {"mass", 1000},
+	"oz": {"mass", 0.028349523125}, "ounce": {"mass", 0.028349523125},
+	"lb": {"mass", 0.45359237}, "lbs": {"mass", 0.45359237}, "pound": {"mass", 0.45359237},
+	"s": {"time", 1}, "sec": {"time", 1}, "second": {"time", 1},
+	"min": {"time", 60}, "minute": {"time", 60},
+	"h": {"time", 3600}, "hr": {"time", 3600}, "hour": {"time", 3600},
+	"day": {"time", 86400},
+	"yr":  {"time", 31557600}, "year": {"time", 31557600},
+}
+
+var (
+	// numberToken matches a written number, with comma, space or underscore
+	// thousands separators and an optional decimal part.
+	numberToken = regexp.MustCompile(`[-+−]?(?:\d{1,3}(?:[, _]\d{3})+|\d+)(?:[.,]\d+)?`)
+	hedges      = []string{"approximately", "approx", "about", "around", "roughly", "nearly", "almost", "circa", "c.", "~", "≈"}
+)
+
+var smallNumbers = map[string]int{
+	"zero": 0, "oh": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7,
+	"eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14,
+	"fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+	"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+}
+
+var scales = map[string]float64{
+	"hundred": 100, "thousand": 1e3, "million": 1e6, "billion": 1e9, "trillion": 1e12,
+}
+
+// ParseQuantity reads the first number in s together with an optional unit
+// and scale word: "about 8,849 m", "1.4 million", "forty-two km".
+func ParseQuantity(s string) (Quantity, bool) {
+	s = stripHedges(strings.ToLower(strings.TrimSpace(s)))
+	if s == "" {
+		return Quantity{}, false
+	}
+	var value float64
+	var rest string
+	if loc := numberToken.FindStringIndex(s); loc != nil {
+		v, ok := parseNumeral(s[loc[0]:loc[1]])
+		if !ok {
+			return Quantity{}, false
+		}
+		if strings.HasPrefix(strings.TrimSpace(s[:loc[0]]), "minus") {
+			v = -v
+		}
+		value, rest = v, s[loc[1]:]
+		words := strings.Fields(rest)
+		if len(words) > 0 {
+			if scale, ok := scales[words[0]]; ok {
+				value *= scale
+				rest = strings.Join(words[1:], " ")
+			}
+		}
+	} else {
+		words := numberWords(s)
+		n := 0
+		for n < len(words) && isNumberWord(words[n]) {
+			n++
+		}
+		neg := false
+		if n == 0 && len(words) > 1 && (words[0] == "minus" || words[0] == "negative") {
+			neg = true
+			words = words[1:]
+			for n < len(words) && isNumberWord(words[n]) {
+				n++
+			}
+		}
+		v, ok := parseWords(words[:n])
+		if !ok {
+			return Quantity{}, false
+		}
+		if neg {
+			v = -v
+		}
+		value, rest = v, strings.Join(words[n:], " ")
+	}
+	q := Quantity{Value: value, Raw: value}
+	if u, ok := lookupUnit(rest); ok {
+		q.Value, q.Dim = value*u.factor, u.dim
+	}
+	return q, true
+}
+
+// ParseNumber is ParseQuantity without the unit.
+func ParseNumber(s string) (float64, bool) {
+	q, ok := ParseQuantity(s)
+	return q.Raw, ok
+}
+
+// SameQuantity reports whether got is within a relative tolerance of want.
+// Units are compared only when both sides name one.
+func SameQuantity(got, want Quantity, tolerance float64) bool {
+	a, b := got.Raw, want.Raw
+	if got.Dim != "" && want.Dim != "" {
+		if got.Dim != want.Dim {
+			return false
+		}
+		a, b = got.Value, want.Value
+	}
+	return math.Abs(a-b) <= tolerance*math.Abs(b)+1e-9
+}
+
+// ParseYear reads a calendar year: "1969", "AD 1066", "44 BC",
+// "nineteen sixty-nine", "twenty oh five". BC years are negative.
+func ParseYear(s string) (int, bool) {
+	s = stripHedges(strings.ToLower(strings.TrimSpace(s)))
+	s = strings.TrimPrefix(s, "in ")
+	s = strings.TrimPrefix(s, "the year ")
+	bc := false
+	for _, suffix := range []string{" bce", " bc", " b.c.e.", " b.c.", " ad", " ce", " a.d.", " c.e."} {
+		if strings.HasSuffix(s, suffix) {
+			bc = strings.Contains(suffix, "b")
+			s = strings.TrimSuffix(s, suffix)
+			break
+		}
+	}
+	for _, prefix := range []string{"ad ", "a.d. "} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	s = strings.TrimSpace(s)
+	year, ok := 0, false
+	if m := numberToken.FindString(s); m != "" && m == s {
+		v, parsed := parseNumeral(m)
+		if parsed && v == math.Trunc(v) {
+			year, ok = int(v), true
+		}
+	} else if words := numberWords(s); len(words) > 0 {
+		year, ok = pairedYear(words)
+		if !ok {
+			var v float64
+			v, ok = parseWords(words)
+			year = int(v)
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	if bc {
+		year = -year
+	}
+	return year, true
+}
+
+// pairedYear reads years spoken as two pairs of digits, as in "nineteen
+// sixty-nine", "eighteen hundred" or "twenty oh five".
+func pairedYear(words []string) (int, bool) {
+	for i := 1; i < len(words); i++ {
+		high, ok := parseWords(words[:i])
+		if !ok || high < 10 || high > 99 {
+			continue
+		}
+		rest := words[i:]
+		if len(rest) == 1 && rest[0] == "hundred" {
+			return int(high) * 100, true
+		}
+		if rest[0] == "oh" || rest[0] == "o" {
+			low, ok := parseWords(rest[1:])
+			if ok && low >= 1 && low <= 9 {
+				return int(high)*100 + int(low), true
+			}
+			continue
+		}
+		low, ok := parseWords(rest)
+		if ok && low >= 10 && low <= 99 {
+			return int(high)*100 + int(low), true
+		}
+	}
+	return 0, false
+}
+
+func stripHedges(s string) string {
+	for changed := true; changed; {
+		changed = false
+		for _, h := range hedges {
+			if strings.HasPrefix(s, h) {
+				s = strings.TrimSpace(strings.TrimPrefix(s, h))
+				changed = true
+			}
+		}
+	}
+	return s
+}
+
+// parseNumeral parses a digit string, deciding whether a comma separates
+// thousands ("8,849") or decimals ("3,14").
+func parseNumeral(s string) (float64, bool) {
+	s = strings.Replace(s, "−", "-", 1)
+	s = strings.NewReplacer(" ", "", "_", "").Replace(s)
+	if i := strings.LastIndex(s, ","); i >= 0 {
+		if len(s)-i-1 == 3 && !strings.Contains(s, ".") {
+			s = strings.ReplaceAll(s, ",", "")
+		} else if strings.Count(s, ",") == 1 && !strings.Contains(s, ".") {
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+func numberWords(s string) []string {
+	s = strings.NewReplacer("-", " ", ",", " ").Replace(s)
+	var words []string
+	for _, w := range strings.Fields(s) {
+		if w != "and" && w != "a" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+func isNumberWord(w string) bool {
+	_, small := smallNumbers[w]
+	_, scale := scales[w]
+	return small || scale
+}
+
+// parseWords reads spelled-out numbers such as "three hundred and twelve" or
+// "1.4 million" written as words.
+func parseWords(words []string) (float64, bool) {
+	if len(words) == 0 {
+		return 0, false
+	}
+	var total, current float64
+	for _, w := range words {
+		if n, ok := smallNumbers[w]; ok {
+			current += float64(n)
+			continue
+		}
+		scale, ok := scales[w]
+		if !ok {
+			return 0, false
+		}
+		if current == 0 {
+			current = 1
+		}
+		if scale == 100 {
+			current *= scale
+			continue
+		}
+		total += current * scale
+		current = 0
+	}
+	return total + current, true
+}
+
+func lookupUnit(rest string) (unit, bool) {
+	words := strings.Fields(strings.Trim(rest, " .,;:!?)("))
+	if len(words) == 0 {
+		return unit{}, false
+	}
+	w := strings.Trim(words[0], ".,;:!?)(")
+	if u, ok := units[w]; ok {
+		return u, true
+	}
+	if strings.HasSuffix(w, "s") {
+		if u, ok := units[strings.TrimSuffix(w, "s")]; ok {
+			return u, true
+		}
+	}
+	if strings.HasSuffix(w, "es") {
+		if u, ok := units[strings.TrimSuffix(w, "es")]; ok {
+			return u, true
+		}
+	}
+	return unit{}, false
+}
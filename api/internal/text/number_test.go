@@ -0,0 +1,74 @@
+package text
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Quantity
+	}{
+		{"1969", Quantity{Value: 1969, Raw: 1969}},
+		{"about 8,849 m", Quantity{Value: 8849, Raw: 8849, Dim: "length"}},
+		{"8.849 km", Quantity{Value: 8849, Raw: 8.849, Dim: "length"}},
+		{"~1.4 million", Quantity{Value: 1.4e6, Raw: 1.4e6}},
+		{"1 000 000 people", Quantity{Value: 1e6, Raw: 1e6}},
+		{"3,14", Quantity{Value: 3.14, Raw: 3.14}},
+		{"forty-two kilograms", Quantity{Value: 42, Raw: 42, Dim: "mass"}},
+		{"three hundred and twelve", Quantity{Value: 312, Raw: 312}},
+		{"minus 40", Quantity{Value: -40, Raw: -40}},
+		{"roughly two thousand feet", Quantity{Value: 609.6, Raw: 2000, Dim: "length"}},
+	}
+	for _, c := range cases {
+		got, ok := ParseQuantity(c.in)
+		if !ok || !SameQuantity(got, c.want, 1e-9) || got.Dim != c.want.Dim || got.Raw != c.want.Raw {
+			t.Fatalf("ParseQuantity(%q) = %+v, %v; want %+v", c.in, got, ok, c.want)
+		}
+	}
+	for _, in := range []string{"", "Paris", "lots"} {
+		if q, ok := ParseQuantity(in); ok {
+			t.Fatalf("ParseQuantity(%q) = %+v, want no number", in, q)
+		}
+	}
+}
+
+func TestSameQuantity(t *testing.T) {
+	want, _ := ParseQuantity("8849 m")
+	for in, same := range map[string]bool{
+		"8849":       true,
+		"8.85 km":    true,
+		"29,032 ft":  true,
+		"8849 kg":    false,
+		"9000 m":     false,
+		"8,849 feet": false,
+	} {
+		got, _ := ParseQuantity(in)
+		if SameQuantity(got, want, 0.01) != same {
+			t.Fatalf("SameQuantity(%q, 8849 m) = %v, want %v", in, !same, same)
+		}
+	}
+}
+
+func TestParseYear(t *testing.T) {
+	cases := map[string]int{
+		"1969":                    1969,
+		"in 1969":                 1969,
+		"AD 1066":                 1066,
+		"44 BC":                   -44,
+		"nineteen sixty-nine":     1969,
+		"Nineteen Sixty Nine":     1969,
+		"twenty oh five":          2005,
+		"eighteen hundred":        1800,
+		"two thousand and twelve": 2012,
+		"circa 1450":              1450,
+	}
+	for in, want := range cases {
+		if got, ok := ParseYear(in); !ok || got != want {
+			t.Fatalf("ParseYear(%q) = %d, %v; want %d", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "1969.5", "the sixties"} {
+		if got, ok := ParseYear(in); ok {
+			t.Fatalf("ParseYear(%q) = %d, want failure", in, got)
+		}
+	}
+}
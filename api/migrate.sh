@@ -19,12 +19,14 @@ fi
 
 case "$CMD" in
   up)
-    echo "[migrate] applying $BASE/001_init.sql"
-    psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f "$BASE/001_init.sql"
-    if [ -f "$BASE/002_add_mcq.sql" ]; then
-      echo "[migrate] applying $BASE/002_add_mcq.sql"
-      psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f "$BASE/002_add_mcq.sql"
-    fi
+    # Migrations are idempotent and re-applied in order on every start.
+    for f in "$BASE"/[0-9][0-9][0-9]_*.sql; do
+      case "$f" in
+        *_down.sql) continue ;;
+      esac
+      echo "[migrate] applying $f"
+      psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f "$f"
+    done
     ;;
   down)
     echo "[migrate] applying $BASE/001_down.sql"
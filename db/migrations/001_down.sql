@@ -1,4 +1,10 @@
+DROP MATERIALIZED VIEW IF EXISTS player_daily_results;
+DROP TABLE IF EXISTS generation_jobs;
+DROP TABLE IF EXISTS disputes;
+DROP TABLE IF EXISTS grade_cache;
+DROP TABLE IF EXISTS choice_embeddings;
 DROP TABLE IF EXISTS answers;
+DROP TABLE IF EXISTS players;
 DROP INDEX IF EXISTS questions_embedding_ivfflat;
 DROP TABLE IF EXISTS questions;
 
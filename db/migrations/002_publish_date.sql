@@ -0,0 +1,19 @@
+-- Each question is served on at most one calendar day.
+ALTER TABLE questions
+  ADD COLUMN IF NOT EXISTS publish_date DATE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS questions_publish_date_idx ON questions (publish_date) WHERE publish_date IS NOT NULL;
+
+-- Backfill once: the newest question of each UTC day becomes that day's question,
+-- matching what "latest row wins" served before.
+UPDATE questions q
+SET publish_date = latest.day
+FROM (
+  SELECT DISTINCT ON ((created_at AT TIME ZONE 'UTC')::date)
+    id,
+    (created_at AT TIME ZONE 'UTC')::date AS day
+  FROM questions
+  ORDER BY (created_at AT TIME ZONE 'UTC')::date, created_at DESC
+) AS latest
+WHERE q.id = latest.id
+  AND NOT EXISTS (SELECT 1 FROM questions WHERE publish_date IS NOT NULL);
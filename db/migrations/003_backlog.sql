@@ -0,0 +1,6 @@
+-- Questions generated ahead of time wait in the backlog (queued_at set,
+-- publish_date NULL) until a day without a question promotes them.
+ALTER TABLE questions
+  ADD COLUMN IF NOT EXISTS queued_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS questions_backlog_idx ON questions (queued_at, created_at) WHERE publish_date IS NULL AND queued_at IS NOT NULL;
@@ -0,0 +1,16 @@
+-- Anonymous players identified by signed tokens.
+CREATE TABLE IF NOT EXISTS players (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE answers
+  ADD COLUMN IF NOT EXISTS user_id UUID REFERENCES players(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS practice BOOLEAN NOT NULL DEFAULT false;
+
+-- Answers recorded before players existed were anonymous; treat them as practice.
+UPDATE answers SET practice = true WHERE user_id IS NULL AND NOT practice;
+
+-- At most one scored answer per player and question.
+CREATE UNIQUE INDEX IF NOT EXISTS answers_scored_user_idx ON answers (question_id, user_id) WHERE user_id IS NOT NULL AND NOT practice;
+CREATE INDEX IF NOT EXISTS answers_user_idx ON answers (user_id, created_at) WHERE user_id IS NOT NULL;
@@ -0,0 +1,14 @@
+-- One row per player and published day they have a scored answer for.
+-- Refreshed periodically by the API (REFRESH ... CONCURRENTLY needs the unique index).
+CREATE MATERIALIZED VIEW IF NOT EXISTS player_daily_results AS
+SELECT
+  a.user_id,
+  q.publish_date,
+  COALESCE(a.score, 0) > 0 AS correct,
+  a.created_at AS answered_at
+FROM answers a
+JOIN questions q ON q.id = a.question_id
+WHERE a.user_id IS NOT NULL AND NOT a.practice AND q.publish_date IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS player_daily_results_key ON player_daily_results (user_id, publish_date);
+CREATE INDEX IF NOT EXISTS player_daily_results_date_idx ON player_daily_results (publish_date);
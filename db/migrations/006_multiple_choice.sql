@@ -0,0 +1,5 @@
+-- Multiple-choice questions keep their shuffled options apart from the
+-- accepted aliases in choices.
+ALTER TABLE questions
+  ADD COLUMN IF NOT EXISTS options JSONB,
+  ADD COLUMN IF NOT EXISTS correct_option INT;
@@ -0,0 +1,3 @@
+-- How answers to a question are read: free_text, numeric, year or date.
+ALTER TABLE questions
+  ADD COLUMN IF NOT EXISTS answer_type TEXT NOT NULL DEFAULT 'free_text';
@@ -0,0 +1,7 @@
+-- One embedding per accepted choice, used to grade answers semantically.
+CREATE TABLE IF NOT EXISTS choice_embeddings (
+  question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+  choice TEXT NOT NULL,
+  embedding VECTOR(1536) NOT NULL,
+  PRIMARY KEY (question_id, choice)
+);
@@ -0,0 +1,11 @@
+-- Verdicts from the semantic tier and the LLM grader, keyed by normalized
+-- answer, so identical answers are graded once and consistently.
+CREATE TABLE IF NOT EXISTS grade_cache (
+  question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+  answer_norm TEXT NOT NULL,
+  score INT NOT NULL,
+  feedback TEXT NOT NULL DEFAULT '',
+  tier TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (question_id, answer_norm)
+);
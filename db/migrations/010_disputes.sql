@@ -0,0 +1,13 @@
+-- Player appeals against a grade, reviewed by an admin.
+CREATE TABLE IF NOT EXISTS disputes (
+  id UUID PRIMARY KEY,
+  answer_id UUID NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+  reason TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'accepted', 'rejected')),
+  resolution_note TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  resolved_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS disputes_answer_idx ON disputes (answer_id);
+CREATE INDEX IF NOT EXISTS disputes_open_idx ON disputes (created_at) WHERE status = 'open';
@@ -0,0 +1,11 @@
+-- Review lifecycle: generated questions start as drafts when review is
+-- required; only published questions are served.
+ALTER TABLE questions
+  ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'approved' CHECK (status IN ('draft', 'approved', 'published', 'rejected')),
+  ADD COLUMN IF NOT EXISTS review_note TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+
+-- Questions that already held a publish date before review existed were live.
+UPDATE questions SET status = 'published' WHERE status = 'approved' AND publish_date IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS questions_draft_idx ON questions (created_at) WHERE status = 'draft';
@@ -0,0 +1,3 @@
+-- Outcome of the blind answering pass run before a generated question is
+-- stored; NULL for questions stored without verification.
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS verification JSONB;
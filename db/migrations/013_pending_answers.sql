@@ -0,0 +1,8 @@
+-- Answers stored while the grader is unavailable stay pending, without a
+-- score, until a background worker grades them.
+ALTER TABLE answers
+  ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'graded' CHECK (status IN ('graded', 'pending')),
+  ADD COLUMN IF NOT EXISTS grade_attempts INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS next_grade_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS answers_pending_idx ON answers (next_grade_at) WHERE status = 'pending';
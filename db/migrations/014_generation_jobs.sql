@@ -0,0 +1,14 @@
+-- Question generation runs in the background; each job keeps its attempts
+-- and why each candidate was rejected.
+CREATE TABLE IF NOT EXISTS generation_jobs (
+  id UUID PRIMARY KEY,
+  status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+  publish_date DATE NOT NULL,
+  force BOOLEAN NOT NULL DEFAULT false,
+  attempts JSONB NOT NULL DEFAULT '[]'::jsonb,
+  question_id UUID REFERENCES questions(id) ON DELETE SET NULL,
+  error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ
+);
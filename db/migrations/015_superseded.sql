@@ -0,0 +1,37 @@
+-- A forced regenerate supersedes the day's question instead of clearing its
+-- publish date, so answers to it still count for that day.
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_status_check;
+ALTER TABLE questions ADD CONSTRAINT questions_status_check
+  CHECK (status IN ('draft', 'approved', 'published', 'rejected', 'superseded'));
+
+-- Only the question serving a day holds its date uniquely. A dated draft
+-- replacing it waits alongside until approved.
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'questions_publish_date_idx' AND indexdef LIKE '%draft%') THEN
+    DROP INDEX IF EXISTS questions_publish_date_idx;
+    CREATE UNIQUE INDEX questions_publish_date_idx ON questions (publish_date)
+      WHERE publish_date IS NOT NULL AND status NOT IN ('draft', 'superseded');
+  END IF;
+END $$;
+
+-- A player may have answered both a superseded question and its
+-- replacement; the day counts once, as correct if either answer was.
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_matviews WHERE matviewname = 'player_daily_results' AND definition LIKE '%bool_or%') THEN
+    DROP MATERIALIZED VIEW IF EXISTS player_daily_results;
+    CREATE MATERIALIZED VIEW player_daily_results AS
+    SELECT
+      a.user_id,
+      q.publish_date,
+      bool_or(COALESCE(a.score, 0) > 0) AS correct,
+      COALESCE(min(a.created_at) FILTER (WHERE a.score > 0), min(a.created_at)) AS answered_at
+    FROM answers a
+    JOIN questions q ON q.id = a.question_id
+    WHERE a.user_id IS NOT NULL AND NOT a.practice AND q.publish_date IS NOT NULL
+    GROUP BY a.user_id, q.publish_date;
+    CREATE UNIQUE INDEX player_daily_results_key ON player_daily_results (user_id, publish_date);
+    CREATE INDEX player_daily_results_date_idx ON player_daily_results (publish_date);
+  END IF;
+END $$;
@@ -0,0 +1,6 @@
+-- Backlog fills run as jobs too. They have no publish date, a target depth
+-- and list the questions they queued.
+ALTER TABLE generation_jobs ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'generate' CHECK (kind IN ('generate', 'backlog'));
+ALTER TABLE generation_jobs ADD COLUMN IF NOT EXISTS target INT NOT NULL DEFAULT 0;
+ALTER TABLE generation_jobs ADD COLUMN IF NOT EXISTS added JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE generation_jobs ALTER COLUMN publish_date DROP NOT NULL;
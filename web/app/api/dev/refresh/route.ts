@@ -1,6 +1,8 @@
 import { NextResponse } from "next/server";
 
 const DEFAULT_API_BASE = "http://localhost:8080";
+const POLL_INTERVAL_MS = 1000;
+const POLL_TIMEOUT_MS = 120_000;
 
 export async function POST() {
   const cronKey = process.env.CRON_KEY;
@@ -8,16 +10,35 @@ export async function POST() {
     return NextResponse.json({ error: "CRON_KEY not set" }, { status: 500 });
   }
   const apiBase = process.env.NEXT_PUBLIC_API_BASE || DEFAULT_API_BASE;
+  const headers = { "X-CRON-KEY": cronKey };
   try {
-    const resp = await fetch(`${apiBase}/v1/admin/generate-today`, {
+    const resp = await fetch(`${apiBase}/v1/admin/generate-today?force=true`, {
       method: "POST",
-      headers: {
-        "X-CRON-KEY": cronKey,
-      },
+      headers,
       cache: "no-store",
     });
-    const payload = await resp.json().catch(() => ({}));
-    return NextResponse.json(payload, { status: resp.status });
+    const job = await resp.json().catch(() => ({}));
+    if (resp.status !== 202) {
+      return NextResponse.json(job, { status: resp.status });
+    }
+    // Generation runs as a job; wait for it so the page can reload onto
+    // the new question.
+    const deadline = Date.now() + POLL_TIMEOUT_MS;
+    while (Date.now() < deadline) {
+      await new Promise((resolve) => setTimeout(resolve, POLL_INTERVAL_MS));
+      const poll = await fetch(`${apiBase}/v1/admin/jobs/${job.id}`, { headers, cache: "no-store" });
+      const payload = await poll.json().catch(() => ({}));
+      if (!poll.ok) {
+        return NextResponse.json(payload, { status: poll.status });
+      }
+      if (payload.status === "succeeded") {
+        return NextResponse.json(payload);
+      }
+      if (payload.status === "failed") {
+        return NextResponse.json(payload, { status: 409 });
+      }
+    }
+    return NextResponse.json({ error: "generation still running", id: job.id }, { status: 504 });
   } catch (err) {
     return NextResponse.json({ error: (err as Error).message || "request failed" }, { status: 500 });
   }
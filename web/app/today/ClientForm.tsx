@@ -1,14 +1,20 @@
 "use client";
 import { useEffect, useMemo, useState } from "react";
 import DevRefreshButton from "./DevRefreshButton";
+import { clearPlayerToken, getPlayerToken } from "./player";
 
 type Props = { apiBase: string; questionId: string };
+type Result = { score: number | null; feedback: string; practice: boolean };
+
+// How often to check on an answer whose grading was delayed.
+const PENDING_POLL_MS = 5000;
 
 export default function ClientForm({ apiBase, questionId }: Props) {
   const [text, setText] = useState("");
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
-  const [result, setResult] = useState<{ score: number; feedback: string } | null>(null);
+  const [result, setResult] = useState<Result | null>(null);
+  const [pending, setPending] = useState<{ id: string; headers: Record<string, string> } | null>(null);
   const [celebrate, setCelebrate] = useState(false);
 
   useEffect(() => {
@@ -17,22 +23,70 @@ export default function ClientForm({ apiBase, questionId }: Props) {
     return () => clearTimeout(id);
   }, [celebrate]);
 
+  // A 202 answer is graded in the background; poll until it has a score.
+  useEffect(() => {
+    if (!pending) return;
+    let cancelled = false;
+    let timer: ReturnType<typeof setTimeout>;
+    async function poll() {
+      try {
+        const res = await fetch(`${apiBase}/v1/answers/${pending!.id}`, { headers: pending!.headers, cache: 'no-store' });
+        if (res.ok) {
+          const data = await res.json();
+          if (cancelled) return;
+          if (data.status === 'graded') {
+            showResult(data);
+            setPending(null);
+            return;
+          }
+        }
+      } catch {
+        // Keep polling; the next try may get through.
+      }
+      if (!cancelled) timer = setTimeout(poll, PENDING_POLL_MS);
+    }
+    timer = setTimeout(poll, PENDING_POLL_MS);
+    return () => {
+      cancelled = true;
+      clearTimeout(timer);
+    };
+  }, [apiBase, pending]);
+
+  function showResult(data: any) {
+    const score = data.score ?? 0;
+    setResult({ score, feedback: data.feedback ?? '', practice: !!data.practice });
+    setCelebrate(score > 0);
+  }
+
   async function onSubmit(e: React.FormEvent) {
     e.preventDefault();
     setLoading(true);
     setError(null);
     setResult(null);
+    setPending(null);
     try {
+      const token = await getPlayerToken(apiBase);
+      const headers: Record<string, string> = { 'Content-Type': 'application/json' };
+      if (token) headers['Authorization'] = `Bearer ${token}`;
       const res = await fetch(`${apiBase}/v1/answers`, {
         method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
+        headers,
         body: JSON.stringify({ question_id: questionId, text }),
       });
-      if (!res.ok) throw new Error(`HTTP ${res.status}`);
+      if (res.status === 401 && token) clearPlayerToken();
+      if (!res.ok) {
+        const body = await res.json().catch(() => ({}));
+        throw new Error(body?.error || `HTTP ${res.status}`);
+      }
       const data = await res.json();
-      const score = data.score ?? 0;
-      setResult({ score, feedback: data.feedback ?? '' });
-      setCelebrate(score > 0);
+      if (res.status === 202 && data.status === 'pending') {
+        setResult({ score: null, feedback: data.feedback ?? '', practice: !!data.practice });
+        const pollHeaders: Record<string, string> = {};
+        if (token) pollHeaders['Authorization'] = `Bearer ${token}`;
+        setPending({ id: data.id, headers: pollHeaders });
+        return;
+      }
+      showResult(data);
     } catch (err: any) {
       setError(err.message || 'Submit failed');
       setCelebrate(false);
@@ -135,7 +189,7 @@ export default function ClientForm({ apiBase, questionId }: Props) {
       </div>
       {result && (
         <div style={{ marginTop: 12, padding: 12, background: '#fff', border: '1px solid #e5e7eb', borderRadius: 6 }}>
-          <div><strong>Score:</strong> {result.score}</div>
+          <div><strong>Score:</strong> {result.score ?? 'pending'}{result.practice && ' (practice — only your first answer counts)'}</div>
           <div style={{ marginTop: 6 }}><strong>Feedback:</strong> {result.feedback}</div>
         </div>
       )}
@@ -1,6 +1,6 @@
 import ClientForm from "./ClientForm";
 
-type Question = { id: string; title: string; text: string; topic: string; created_at: string };
+type Question = { id: string; title: string; text: string; topic: string; created_at: string; options?: string[] };
 
 async function getQuestion(apiBase: string): Promise<Question | null> {
   const res = await fetch(`${apiBase}/v1/question/today`, { cache: 'no-store' });
@@ -21,6 +21,13 @@ export default async function Page() {
           <div style={{ color: '#6b7280' }}>{q.topic}</div>
           <h2 style={{ margin: '6px 0 8px' }}>{q.title}</h2>
           <p style={{ background: '#fff', border: '1px solid #e5e7eb', padding: 12, borderRadius: 6 }}>{q.text}</p>
+          {q.options && q.options.length > 0 && (
+            <ol type="A" style={{ margin: '0 0 12px', paddingLeft: 24 }}>
+              {q.options.map((o) => (
+                <li key={o}>{o}</li>
+              ))}
+            </ol>
+          )}
           <ClientForm apiBase={apiBase} questionId={q.id} />
         </div>
       )}
@@ -0,0 +1,26 @@
+const STORAGE_KEY = "qotd_player_token";
+
+// getPlayerToken returns this browser's signed player token, registering a new
+// anonymous player on first use.
+export async function getPlayerToken(apiBase: string): Promise<string | null> {
+  try {
+    const existing = window.localStorage.getItem(STORAGE_KEY);
+    if (existing) return existing;
+    const res = await fetch(`${apiBase}/v1/players`, { method: "POST" });
+    if (!res.ok) return null;
+    const data = await res.json();
+    if (typeof data.token !== "string") return null;
+    window.localStorage.setItem(STORAGE_KEY, data.token);
+    return data.token;
+  } catch {
+    return null;
+  }
+}
+
+export function clearPlayerToken() {
+  try {
+    window.localStorage.removeItem(STORAGE_KEY);
+  } catch {
+    // storage unavailable (private mode); nothing to clear
+  }
+}